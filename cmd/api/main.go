@@ -8,6 +8,10 @@
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key created with POST /user/api-keys, limited to its scopes.
 package main
 
 import (
@@ -15,40 +15,24 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/url": {
+        "/admin/clicks": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a paginated list of all shortened URLs in the system",
+                "description": "Get how many click events are waiting to be written, and how many were dropped since startup because the in-memory buffer was full or failed to be written to the database. Click counts are written with the events, so dropped and failed events are not counted either.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Admin"
                 ],
-                "summary": "List all shortened URLs (Admin only)",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 50,
-                        "description": "Number of results to return",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of results to skip",
-                        "name": "offset",
-                        "in": "query"
-                    }
-                ],
+                "summary": "Get click pipeline status (Admin only)",
                 "responses": {
                     "200": {
-                        "description": "List of URLs with pagination metadata",
+                        "description": "Click pipeline status",
                         "schema": {
                             "allOf": [
                                 {
@@ -58,10 +42,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/domain.URL"
-                                            }
+                                            "$ref": "#/definitions/domain.ClickPipelineStats"
                                         }
                                     }
                                 }
@@ -74,8 +55,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.APIResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Forbidden - not an admin",
                         "schema": {
                             "$ref": "#/definitions/domain.APIResponse"
                         }
@@ -83,97 +64,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Authenticate user with username and password",
-                "consumes": [
-                    "application/json"
+        "/admin/url": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get a paginated list of all shortened URLs in the system",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "User login",
+                "summary": "List all shortened URLs (Moderator or admin)",
                 "parameters": [
                     {
-                        "description": "Login request with username and password",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.LoginRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Successfully authenticated, returns JWT token",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/domain.APIResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/domain.AuthResponse"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request body",
-                        "schema": {
-                            "$ref": "#/definitions/domain.APIResponse"
-                        }
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of results to return",
+                        "name": "limit",
+                        "in": "query"
                     },
-                    "401": {
-                        "description": "Invalid username or password",
-                        "schema": {
-                            "$ref": "#/definitions/domain.APIResponse"
-                        }
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/domain.APIResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/register": {
-            "post": {
-                "description": "Create a new user account with username and password",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Authentication"
-                ],
-                "summary": "Register a new user",
-                "parameters": [
                     {
-                        "description": "Registration request with username and password",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.RegisterRequest"
-                        }
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include soft-deleted URLs",
+                        "name": "include_deleted",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully registered, returns JWT token",
+                    "200": {
+                        "description": "List of URLs with pagination metadata",
                         "schema": {
                             "allOf": [
                                 {
@@ -183,21 +114,24 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.AuthResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.URL"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request or validation error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/domain.APIResponse"
                         }
                     },
-                    "409": {
-                        "description": "Username already exists",
+                    "403": {
+                        "description": "Forbidden - not a moderator or admin",
                         "schema": {
                             "$ref": "#/definitions/domain.APIResponse"
                         }
@@ -211,21 +145,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/url/links/{alias}": {
-            "get": {
+        "/admin/url/{alias}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get detailed information about a shortened URL including click count (only owner can view)",
+                "description": "Remove a short URL from the database, including soft-deleted ones, and free its alias",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "URL Shortener"
+                    "Admin"
                 ],
-                "summary": "Get URL information",
+                "summary": "Permanently delete a shortened URL (Admin only)",
                 "parameters": [
                     {
                         "type": "string",
@@ -233,25 +167,19 @@ const docTemplate = `{
                         "name": "alias",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "URL information",
+                        "description": "URL purged",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/domain.APIResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/domain.URLInfoResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/domain.APIResponse"
                         }
                     },
                     "401": {
@@ -261,7 +189,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - not owner",
+                        "description": "Forbidden - not an admin",
                         "schema": {
                             "$ref": "#/definitions/domain.APIResponse"
                         }
@@ -281,21 +209,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/url/my-links": {
+        "/admin/users": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a paginated list of all URLs created by the authenticated user",
+                "description": "Get a paginated list of all users and their roles",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "URL Shortener"
+                    "Admin"
                 ],
-                "summary": "Get URLs created by authenticated user",
+                "summary": "List users (Admin only)",
                 "parameters": [
                     {
                         "type": "integer",
@@ -314,7 +242,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of user's URLs with pagination metadata",
+                        "description": "List of users with pagination metadata",
                         "schema": {
                             "allOf": [
                                 {
@@ -326,7 +254,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/domain.URL"
+                                                "$ref": "#/definitions/domain.User"
                                             }
                                         }
                                     }
@@ -340,6 +268,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.APIResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden - not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -349,14 +283,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/url/shorten": {
-            "post": {
+        "/admin/users/{id}/role": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a short URL from a long URL with optional custom alias",
+                "description": "Make a user a regular user, a moderator or an admin. The change applies from the user's next request. The last admin cannot be demoted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -364,23 +298,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "URL Shortener"
+                    "Admin"
                 ],
-                "summary": "Create a shortened URL",
+                "summary": "Change the role of a user (Admin only)",
                 "parameters": [
                     {
-                        "description": "URL to shorten and optional alias",
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.ShortenRequest"
+                            "$ref": "#/definitions/domain.UpdateUserRoleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully created short URL",
+                        "description": "Updated user",
                         "schema": {
                             "allOf": [
                                 {
@@ -390,7 +331,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.ShortenResponse"
+                                            "$ref": "#/definitions/domain.User"
                                         }
                                     }
                                 }
@@ -398,7 +339,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request or validation error",
+                        "description": "Invalid role",
                         "schema": {
                             "$ref": "#/definitions/domain.APIResponse"
                         }
@@ -409,8 +350,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.APIResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden - not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
                     "409": {
-                        "description": "Alias already exists",
+                        "description": "Last admin",
                         "schema": {
                             "$ref": "#/definitions/domain.APIResponse"
                         }
@@ -424,28 +377,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/{alias}": {
-            "get": {
-                "description": "Redirect to the original URL using the short alias",
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate user with username and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "URL Shortener"
+                    "Authentication"
                 ],
-                "summary": "Redirect to original URL",
+                "summary": "User login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Short URL alias",
-                        "name": "alias",
-                        "in": "path",
-                        "required": true
+                        "description": "Login request with username and password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "302": {
-                        "description": "Redirects to original URL"
+                    "200": {
+                        "description": "Successfully authenticated, returns JWT token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
-                    "404": {
-                        "description": "Short URL not found",
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid username or password",
                         "schema": {
                             "$ref": "#/definitions/domain.APIResponse"
                         }
@@ -458,175 +440,3555 @@ const docTemplate = `{
                     }
                 }
             }
-        }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Create a new user account with username and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "Registration request with username and password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully registered, returns JWT token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Username already exists",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the custom domains of the authenticated user and their verification state",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Domains"
+                ],
+                "summary": "List custom domains",
+                "responses": {
+                    "200": {
+                        "description": "Custom domains",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.DomainResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a branded domain, such as go.example.com, to serve short URLs from. The domain must be verified by creating the returned DNS TXT record and calling the verify endpoint, and pointed at the shortener, before short URLs can use it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Domains"
+                ],
+                "summary": "Add a custom domain",
+                "parameters": [
+                    {
+                        "description": "Domain to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RegisterDomainRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Domain added, with its verification record",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.DomainResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid hostname",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Domain already registered",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{hostname}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a custom domain of the authenticated user. Domains that still have short URLs, including deleted ones, cannot be removed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Domains"
+                ],
+                "summary": "Remove a custom domain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Domain hostname",
+                        "name": "hostname",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Domain removed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Domain not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Domain still has short URLs",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/domains/{hostname}/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Look up the verification DNS TXT record of a custom domain and mark the domain verified if it holds the expected value. DNS changes can take a while to propagate; the call can be retried.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Domains"
+                ],
+                "summary": "Verify a custom domain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Domain hostname",
+                        "name": "hostname",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Domain verified",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.DomainResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Verification record not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Domain not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Domain already verified by another user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/links/{alias}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get detailed information about a shortened URL including click count (owner or workspace members can view)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Shortener"
+                ],
+                "summary": "Get URL information",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URL information",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.URLInfoResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Soft-delete a short URL. The alias stays reserved and redirects to it return 410 Gone (owner or workspace editors and owners can delete). With purge=true the URL, soft-deleted or not, and its click events are removed permanently and the alias is freed; only the creator and workspace owners can purge. Custom domains can only be removed once they have no URLs left, deleted ones included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Shortener"
+                ],
+                "summary": "Delete a shortened URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Remove the URL permanently",
+                        "name": "purge",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URL deleted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change the destination and other mutable settings of a short URL without changing its alias (owner or workspace editors and owners can update). Setting workspace_id moves the URL into a workspace the user is an editor or owner of; 0 moves it back to the personal URLs of its creator. Only the creator and owners of the current workspace can move a URL out of it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Shortener"
+                ],
+                "summary": "Update a shortened URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateURLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated URL information",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.URLInfoResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/links/{alias}/stats/devices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get clicks on a short URL grouped by device class (mobile, tablet, desktop, bot), OS family or browser family, most clicked first (owner or workspace members can view)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Get device, OS and browser breakdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "device",
+                            "os",
+                            "browser"
+                        ],
+                        "type": "string",
+                        "default": "device",
+                        "description": "Dimension to group by",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of results to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include bot clicks",
+                        "name": "include_bots",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Click breakdown with pagination metadata",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.BreakdownItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/links/{alias}/stats/geo": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get clicks on a short URL grouped by country (ISO 3166-1 alpha-2 code), region or city, most clicked first (owner or workspace members can view). Clicks that could not be geolocated are reported as \"(none)\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Get geographic breakdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "country",
+                            "region",
+                            "city"
+                        ],
+                        "type": "string",
+                        "default": "country",
+                        "description": "Dimension to group by",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of results to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include bot clicks",
+                        "name": "include_bots",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Click breakdown with pagination metadata",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.BreakdownItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/links/{alias}/stats/referrers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get clicks on a short URL grouped by referring host or by the UTM parameters of the inbound request, most clicked first (owner or workspace members can view). Direct visits are reported as \"(direct)\", unparsable referrers as \"(unknown)\", referrals from the shortener itself as \"(self)\" and clicks without the UTM parameter as \"(none)\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Get referrer and campaign breakdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "host",
+                            "utm_source",
+                            "utm_medium",
+                            "utm_campaign"
+                        ],
+                        "type": "string",
+                        "default": "host",
+                        "description": "Dimension to group by",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of results to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include bot clicks",
+                        "name": "include_bots",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Click breakdown with pagination metadata",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.BreakdownItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/links/{alias}/stats/rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get clicks on a short URL grouped by the targeting rule that picked their destination, such as \"device:ios\" or \"geo:2\", most clicked first (owner or workspace members can view). Clicks sent to the original URL are reported as \"(none)\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Get targeting rule breakdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of results to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include bot clicks",
+                        "name": "include_bots",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Click breakdown with pagination metadata",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.BreakdownItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/links/{alias}/stats/timeseries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get click counts for a short URL bucketed by hour, day or week, with empty buckets zero-filled (owner or workspace members can view)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Get click time series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC 3339 or YYYY-MM-DD), defaults to 30 intervals before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range, exclusive (RFC 3339 or YYYY-MM-DD), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "hour",
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "default": "day",
+                        "description": "Bucket size",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone used for bucketing",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include bot clicks",
+                        "name": "include_bots",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Click time series",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TimeSeriesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/links/{alias}/stats/uniques": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the approximate number of distinct visitors of a short URL per UTC day, with days without visitors zero-filled, and over the whole range (owner or workspace members can view). Visitors are told apart by a salted hash of IP address and User-Agent, and counted with HyperLogLog sketches, so counts are accurate to within a few percent. Bot clicks are not counted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Get daily unique visitors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC 3339 or YYYY-MM-DD), defaults to 30 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range, exclusive (RFC 3339 or YYYY-MM-DD), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daily unique visitors",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.UniqueVisitorsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/links/{alias}/stats/variants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the clicks sent to each variant of a short URL with weighted destinations, in configured order, followed by removed variants that still have clicks (owner or workspace members can view). Visitors matching a device or geo rule are not counted towards any variant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Get clicks per variant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom domain of the short URL, if not on BASE_URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include bot clicks",
+                        "name": "include_bots",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Clicks per variant",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.VariantStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not the owner or a workspace member with a sufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/my-links": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a paginated list of the personal URLs created by the authenticated user, or of the URLs of a workspace the user is a member of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Shortener"
+                ],
+                "summary": "Get URLs created by authenticated user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List the URLs of this workspace instead",
+                        "name": "workspace_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of results to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of user's URLs with pagination metadata",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.URL"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/shorten": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a short URL from a long URL with optional custom alias",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Shortener"
+                ],
+                "summary": "Create a shortened URL",
+                "parameters": [
+                    {
+                        "description": "URL to shorten and optional alias",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ShortenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully created short URL",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ShortenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - viewer of the workspace",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Alias already exists",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the API keys of the authenticated user, including revoked ones, newest first. Keys are identified by their first characters; the full keys cannot be retrieved.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Keys"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "API keys",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.APIKey"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a long-lived API key for programmatic access, limited to the given scopes: links:write (create, update and delete short URLs), links:read (read short URLs) and stats:read (read click statistics). The key is only shown in this response. Send it in the X-API-Key header, or as \"Authorization: ApiKey \u003ckey\u003e\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Keys"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "Key name and scopes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API key created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CreateAPIKeyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid name or scopes",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently disable an API key of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Keys"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API key revoked",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found or already revoked",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/settings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the defaults applied to new short URLs of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get user settings",
+                "responses": {
+                    "200": {
+                        "description": "User settings",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.UserSettings"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the defaults applied to new short URLs of the authenticated user. Only the fields present in the request body are changed; existing URLs keep their settings.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update user settings",
+                "parameters": [
+                    {
+                        "description": "Settings to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateUserSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated user settings",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.UserSettings"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/utm-presets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the saved UTM presets of the authenticated user, ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "List UTM presets",
+                "responses": {
+                    "200": {
+                        "description": "UTM presets",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.UTMPreset"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/utm-presets/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Save a named set of UTM parameters that can be applied to new short URLs with utm_preset. Values are stored in canonical form: trimmed, lower-cased, with inner whitespace replaced by underscores. Saving an existing name replaces its parameters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Create or replace a UTM preset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preset name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UTM parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UTMParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Saved UTM preset",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.UTMPreset"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a saved UTM preset. Short URLs created with it keep their UTM parameters.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete a UTM preset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preset name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "UTM preset deleted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "UTM preset not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the workspaces the authenticated user is a member of, with the user's role in each",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "List workspaces",
+                "responses": {
+                    "200": {
+                        "description": "Workspaces",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Workspace"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a workspace to share short URLs with a team. The authenticated user becomes its first owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Create a workspace",
+                "parameters": [
+                    {
+                        "description": "Workspace to create",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateWorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Workspace created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Workspace"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid workspace name",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a workspace and its memberships (owners only). Workspaces that still have short URLs cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Delete a workspace",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Workspace deleted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not an owner",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Workspace still has short URLs",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the members of a workspace and their roles (members only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "List workspace members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Workspace members",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WorkspaceMember"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a user to a workspace as owner, editor or viewer (owners only). Viewers see the workspace's URLs and their stats, editors also create, change and delete them, and owners also manage members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Add a workspace member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User and role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.AddWorkspaceMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member added",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WorkspaceMember"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not an owner",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User is already a member",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/members/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a member from a workspace (owners only), or leave a workspace by passing one's own user ID. The last owner cannot leave. URLs the member created stay in the workspace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Remove a workspace member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID of the member",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member removed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not an owner",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace or member not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Last owner of the workspace",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the role of a workspace member (owners only). The last owner cannot step down.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Change a workspace member's role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID of the member",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateWorkspaceMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role changed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - not an owner",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace or member not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Last owner of the workspace",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{alias}": {
+            "get": {
+                "description": "Redirect to the original URL using the short alias. Crawlers, link previewers, HEAD requests and prefetches are counted as bot clicks. Appending \"+\" to the alias, or passing preview=1, shows a preview page with the destination instead of redirecting; links with force_preview always show it until the visitor confirms. Other query parameters are passed on to the destination as set by the link's query_policy. Crawlers that unfurl shared links, such as Slackbot or Twitterbot, get a page with the link's og_title, og_description and og_image instead of the redirect when any is set.",
+                "tags": [
+                    "URL Shortener"
+                ],
+                "summary": "Redirect to original URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias, optionally followed by + for a preview",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Show the preview page instead of redirecting",
+                        "name": "preview",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the forced preview page after the visitor confirmed",
+                        "name": "confirm",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password prompt for protected links, preview page, or social preview page for crawlers unfurling links with og_* overrides (HTML page)"
+                    },
+                    "301": {
+                        "description": "Redirects to original URL (permanent, cacheable)"
+                    },
+                    "302": {
+                        "description": "Redirects to original URL (temporary, default)"
+                    },
+                    "307": {
+                        "description": "Redirects to original URL (temporary, method preserved)"
+                    },
+                    "308": {
+                        "description": "Redirects to original URL (permanent, method preserved, cacheable)"
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Short URL has been deleted or has expired without a fallback URL (HTML page)"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Check the password of a protected short URL submitted from the password prompt. On success, the unlock is remembered in a signed, short-lived cookie and the visitor is redirected to the original URL. Wrong passwords are rate limited per alias.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "URL Shortener"
+                ],
+                "summary": "Unlock a password-protected short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL alias",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link password",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "303": {
+                        "description": "Redirects to original URL"
+                    },
+                    "403": {
+                        "description": "Incorrect password (HTML page)"
+                    },
+                    "404": {
+                        "description": "Short URL not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Short URL has been deleted or has expired without a fallback URL (HTML page)"
+                    },
+                    "429": {
+                        "description": "Too many incorrect passwords (HTML page)"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIResponse"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "domain.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "first characters of the key",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "domain.APIResponse": {
             "type": "object",
             "properties": {
-                "data": {},
-                "error": {
-                    "$ref": "#/definitions/domain.ErrorDetails"
+                "data": {},
+                "error": {
+                    "$ref": "#/definitions/domain.ErrorDetails"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "meta": {
+                    "$ref": "#/definitions/domain.Meta"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.AddWorkspaceMemberRequest": {
+            "type": "object",
+            "required": [
+                "role",
+                "username"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BreakdownItem": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ClickPipelineStats": {
+            "type": "object",
+            "properties": {
+                "dropped_events": {
+                    "type": "integer"
+                },
+                "failed_events": {
+                    "type": "integer"
+                },
+                "pending_events": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "first characters of the key",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.CreateWorkspaceRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DNSRecord": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeviceRule": {
+            "type": "object",
+            "properties": {
+                "platform": {
+                    "description": "ios, android or desktop",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DomainResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "hostname": {
+                    "type": "string"
+                },
+                "verification_record": {
+                    "$ref": "#/definitions/domain.DNSRecord"
+                },
+                "verified": {
+                    "type": "boolean"
+                },
+                "verified_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ErrorDetails": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GeoRule": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "description": "ISO 3166-1 alpha-2 codes, e.g. [\"DE\", \"AT\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Meta": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.RegisterDomainRequest": {
+            "type": "object",
+            "required": [
+                "hostname"
+            ],
+            "properties": {
+                "hostname": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ShortenRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "device_rules": {
+                    "description": "DeviceRules send visitors on iOS, Android or desktop to other\ndestinations. They take precedence over geo rules.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeviceRule"
+                    }
+                },
+                "domain": {
+                    "description": "Domain is a verified custom domain of the user to serve the short URL\nfrom. Aliases are unique per domain. It defaults to BASE_URL.",
+                    "type": "string"
+                },
+                "expired_redirect_url": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "force_preview": {
+                    "description": "ForcePreview always shows the preview page before redirecting, for\ndestinations visitors should check first",
+                    "type": "boolean"
+                },
+                "geo_rules": {
+                    "description": "GeoRules send visitors from the listed countries to other destinations.\nThey are evaluated in order; the first match wins.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GeoRule"
+                    }
+                },
+                "max_clicks": {
+                    "type": "integer"
+                },
+                "og_description": {
+                    "type": "string"
+                },
+                "og_image": {
+                    "type": "string"
+                },
+                "og_title": {
+                    "description": "Open Graph and Twitter Card overrides. When set, crawlers that unfurl\nshared links get a page with these instead of the redirect.",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password protects the link: visitors must enter it before being\nredirected",
+                    "type": "string"
+                },
+                "query_policy": {
+                    "description": "QueryPolicy decides whether the query string of a request for the\nshort link is passed on to the destination: drop (default), append,\nmerge (destination values win) or override (request values win)",
+                    "type": "string"
+                },
+                "redirect_type": {
+                    "description": "RedirectType is the HTTP status used to redirect (301, 302, 307 or\n308). It defaults to the owner's default redirect type.",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Title of the destination page, shown on the preview page",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "utm": {
+                    "description": "UTM parameters are set on the destination URL, replacing any it already\nhas. UTMPreset names a saved preset to start from; fields set in UTM\ntake precedence over the preset.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.UTMParams"
+                        }
+                    ]
+                },
+                "utm_preset": {
+                    "type": "string"
+                },
+                "variants": {
+                    "description": "Variants split visitors between weighted destinations, e.g. 70/30.\nVisitors matching a device or geo rule follow the rule instead.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Variant"
+                    }
+                },
+                "workspace_id": {
+                    "description": "WorkspaceID creates the short URL in a workspace the user is an editor\nor owner of, instead of as a personal URL",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ShortenResponse": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "short_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TimeSeriesPoint": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TimeSeriesResponse": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TimeSeriesPoint"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "tz": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.URL": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "bot_click_count": {
+                    "description": "crawlers, link previewers and prefetches",
+                    "type": "integer"
+                },
+                "click_count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "device_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeviceRule"
+                    }
+                },
+                "domain": {
+                    "description": "custom domain hostname, if any",
+                    "type": "string"
+                },
+                "expired_redirect_url": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Optional lifetime. Once the link expires, visitors are sent to\nExpiredRedirectURL, or shown an expiry page if it is empty.",
+                    "type": "string"
+                },
+                "force_preview": {
+                    "description": "always show the preview page before redirecting",
+                    "type": "boolean"
+                },
+                "geo_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GeoRule"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_clicks": {
+                    "type": "integer"
+                },
+                "og_description": {
+                    "type": "string"
+                },
+                "og_image": {
+                    "type": "string"
+                },
+                "og_title": {
+                    "description": "Social preview overrides, shown by crawlers that unfurl shared links",
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "query_policy": {
+                    "description": "drop, append, merge or override",
+                    "type": "string"
+                },
+                "redirect_type": {
+                    "description": "301, 302, 307 or 308",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "title of the destination page, if known",
+                    "type": "string"
+                },
+                "unique_visitors": {
+                    "description": "approximate, from a HyperLogLog sketch",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "creator",
+                    "type": "integer"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Variant"
+                    }
+                },
+                "workspace_id": {
+                    "description": "zero for personal URLs of the creator",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.URLInfoResponse": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
                 },
-                "message": {
+                "bot_click_count": {
+                    "type": "integer"
+                },
+                "click_count": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "meta": {
-                    "$ref": "#/definitions/domain.Meta"
+                "device_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeviceRule"
+                    }
                 },
-                "success": {
+                "domain": {
+                    "type": "string"
+                },
+                "expired_redirect_url": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "force_preview": {
+                    "type": "boolean"
+                },
+                "geo_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GeoRule"
+                    }
+                },
+                "max_clicks": {
+                    "type": "integer"
+                },
+                "og_description": {
+                    "type": "string"
+                },
+                "og_image": {
+                    "type": "string"
+                },
+                "og_title": {
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "password_protected": {
                     "type": "boolean"
+                },
+                "query_policy": {
+                    "type": "string"
+                },
+                "redirect_type": {
+                    "type": "integer"
+                },
+                "short_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "unique_visitors": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Variant"
+                    }
+                },
+                "workspace_id": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.AuthResponse": {
+        "domain.UTMParams": {
             "type": "object",
             "properties": {
-                "token": {
+                "campaign": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "integer"
+                "content": {
+                    "type": "string"
                 },
-                "username": {
+                "medium": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "term": {
                     "type": "string"
                 }
             }
         },
-        "domain.ErrorDetails": {
+        "domain.UTMPreset": {
             "type": "object",
             "properties": {
-                "code": {
+                "campaign": {
                     "type": "string"
                 },
-                "details": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "medium": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "term": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "domain.LoginRequest": {
+        "domain.UniqueVisitorsPoint": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
+                "day": {
                     "type": "string"
                 },
-                "username": {
+                "unique_visitors": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.UniqueVisitorsResponse": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UniqueVisitorsPoint"
+                    }
+                },
+                "to": {
                     "type": "string"
+                },
+                "unique_visitors": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.Meta": {
+        "domain.UpdateURLRequest": {
             "type": "object",
             "properties": {
-                "limit": {
+                "clear_expires_at": {
+                    "description": "ClearExpiresAt removes the expiration time, ClearMaxClicks the click\nlimit. They cannot be combined with setting the same field.",
+                    "type": "boolean"
+                },
+                "clear_max_clicks": {
+                    "type": "boolean"
+                },
+                "device_rules": {
+                    "description": "DeviceRules replaces all device rules; an empty list removes them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeviceRule"
+                    }
+                },
+                "expired_redirect_url": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "force_preview": {
+                    "type": "boolean"
+                },
+                "geo_rules": {
+                    "description": "GeoRules replaces all geo rules; an empty list removes them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GeoRule"
+                    }
+                },
+                "max_clicks": {
                     "type": "integer"
                 },
-                "page": {
+                "og_description": {
+                    "type": "string"
+                },
+                "og_image": {
+                    "type": "string"
+                },
+                "og_title": {
+                    "description": "Open Graph overrides; an empty string removes one",
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password sets a new password, or removes protection when empty",
+                    "type": "string"
+                },
+                "query_policy": {
+                    "type": "string"
+                },
+                "redirect_type": {
                     "type": "integer"
                 },
-                "total": {
+                "title": {
+                    "type": "string"
+                },
+                "variants": {
+                    "description": "Variants replaces all variants; an empty list removes them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Variant"
+                    }
+                },
+                "workspace_id": {
+                    "description": "WorkspaceID moves the URL into a workspace the user is an editor or\nowner of. Zero moves it back to the personal URLs of its creator. Only\nthe creator and owners of the current workspace can move a URL out of\nit.",
                     "type": "integer"
                 }
             }
         },
-        "domain.RegisterRequest": {
+        "domain.UpdateUserRoleRequest": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "role"
             ],
             "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
+                "role": {
                     "type": "string"
                 }
             }
         },
-        "domain.ShortenRequest": {
+        "domain.UpdateUserSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "default_redirect_type": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.UpdateWorkspaceMemberRequest": {
             "type": "object",
             "required": [
-                "url"
+                "role"
             ],
             "properties": {
-                "alias": {
-                    "type": "string"
-                },
-                "url": {
+                "role": {
                     "type": "string"
                 }
             }
         },
-        "domain.ShortenResponse": {
+        "domain.User": {
             "type": "object",
             "properties": {
-                "alias": {
+                "created_at": {
                     "type": "string"
                 },
-                "original_url": {
+                "default_redirect_type": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "user, moderator or admin",
                     "type": "string"
                 },
-                "short_url": {
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "domain.URL": {
+        "domain.UserSettings": {
             "type": "object",
             "properties": {
-                "alias": {
-                    "type": "string"
-                },
-                "click_count": {
+                "default_redirect_type": {
                     "type": "integer"
+                }
+            }
+        },
+        "domain.Variant": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "defaults to A, B, C, ... by position",
+                    "type": "string"
                 },
-                "created_at": {
+                "url": {
                     "type": "string"
                 },
-                "id": {
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.VariantStats": {
+            "type": "object",
+            "properties": {
+                "clicks": {
                     "type": "integer"
                 },
-                "original_url": {
+                "name": {
                     "type": "string"
                 },
-                "updated_at": {
+                "url": {
                     "type": "string"
                 },
-                "user_id": {
+                "weight": {
+                    "description": "zero for variants that were removed",
                     "type": "integer"
                 }
             }
         },
-        "domain.URLInfoResponse": {
+        "domain.VariantStatsResponse": {
             "type": "object",
             "properties": {
                 "alias": {
                     "type": "string"
                 },
-                "click_count": {
+                "clicks": {
                     "type": "integer"
                 },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.VariantStats"
+                    }
+                }
+            }
+        },
+        "domain.Workspace": {
+            "type": "object",
+            "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "original_url": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "role of the requesting user",
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.WorkspaceMember": {
+            "type": "object",
+            "properties": {
+                "joined_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
                 },
                 "user_id": {
                     "type": "integer"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key created with POST /user/api-keys, limited to its scopes.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "Type \"Bearer\" followed by a space and JWT token.",
             "type": "apiKey",
@@ -1,16 +1,22 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	Server struct {
-		Port    string
-		BaseURL string
+		Port           string
+		BaseURL        string
+		TrustedProxies []string
 	}
 	Database struct {
 		URL string
@@ -22,6 +28,32 @@ type Config struct {
 	Shortener struct {
 		Base62Chars string
 	}
+	Admin struct {
+		Usernames         []string
+		BootstrapUsername string
+		BootstrapPassword string
+	}
+	Analytics struct {
+		IPHashSalt         string
+		ClickBufferSize    int
+		ClickBatchSize     int
+		ClickFlushInterval string
+	}
+	GeoIP struct {
+		DatabasePath   string
+		ReloadInterval string
+	}
+	LinkPassword struct {
+		UnlockSecret  string
+		UnlockTTL     string
+		MaxAttempts   int
+		AttemptWindow string
+	}
+	Cache struct {
+		URLSize        int
+		URLTTL         string
+		URLNegativeTTL string
+	}
 }
 
 func LoadConfig() *Config {
@@ -35,6 +67,7 @@ func LoadConfig() *Config {
 	// Load Server configuration
 	cfg.Server.Port = getEnv("SERVER_PORT", "8080")
 	cfg.Server.BaseURL = getEnv("BASE_URL", "http://localhost:"+cfg.Server.Port)
+	cfg.Server.TrustedProxies = getEnvList("TRUSTED_PROXIES")
 
 	// Load Database configuration
 	cfg.Database.URL = getEnv("DATABASE_URL", "")
@@ -52,6 +85,36 @@ func LoadConfig() *Config {
 	// Load Shortener configuration
 	cfg.Shortener.Base62Chars = getEnv("BASE62_CHARS", "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
 
+	// Load Admin configuration
+	cfg.Admin.Usernames = getEnvList("ADMIN_USERNAMES")
+	cfg.Admin.BootstrapUsername = getEnv("ADMIN_BOOTSTRAP_USERNAME", "")
+	cfg.Admin.BootstrapPassword = getEnv("ADMIN_BOOTSTRAP_PASSWORD", "")
+
+	// Load Analytics configuration
+	// Without a dedicated salt, one is derived from JWT_SECRET, so IP hashes
+	// never share a key with JWTs
+	cfg.Analytics.IPHashSalt = getEnv("IP_HASH_SALT", deriveSecret(cfg.JWT.Secret, "ip-hash"))
+	cfg.Analytics.ClickBufferSize = getEnvInt("CLICK_BUFFER_SIZE", 10000)
+	cfg.Analytics.ClickBatchSize = getEnvInt("CLICK_BATCH_SIZE", 500)
+	cfg.Analytics.ClickFlushInterval = getEnv("CLICK_FLUSH_INTERVAL", "1s")
+
+	// Load GeoIP configuration
+	cfg.GeoIP.DatabasePath = getEnv("GEOIP_DATABASE_PATH", "")
+	cfg.GeoIP.ReloadInterval = getEnv("GEOIP_RELOAD_INTERVAL", "1m")
+
+	// Load Link password configuration
+	// Without a dedicated secret, one is derived from JWT_SECRET, so unlock
+	// tokens never share a key with JWTs
+	cfg.LinkPassword.UnlockSecret = getEnv("LINK_UNLOCK_SECRET", deriveSecret(cfg.JWT.Secret, "link-unlock"))
+	cfg.LinkPassword.UnlockTTL = getEnv("LINK_UNLOCK_TTL", "15m")
+	cfg.LinkPassword.MaxAttempts = getEnvInt("LINK_PASSWORD_MAX_ATTEMPTS", 5)
+	cfg.LinkPassword.AttemptWindow = getEnv("LINK_PASSWORD_ATTEMPT_WINDOW", "15m")
+
+	// Load Cache configuration
+	cfg.Cache.URLSize = getEnvInt("URL_CACHE_SIZE", 10000)
+	cfg.Cache.URLTTL = getEnv("URL_CACHE_TTL", "30s")
+	cfg.Cache.URLNegativeTTL = getEnv("URL_CACHE_NEGATIVE_TTL", "5s")
+
 	return cfg
 }
 
@@ -62,3 +125,38 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt reads an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvList reads a comma-separated environment variable into a slice,
+// skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// deriveSecret derives a key for one purpose from a master secret, with
+// HMAC-SHA256 over a label naming the purpose
+func deriveSecret(secret, label string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("url-shortener/" + label))
+	return hex.EncodeToString(mac.Sum(nil))
+}
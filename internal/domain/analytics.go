@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Time series bucket sizes
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// Breakdown dimensions, as accepted by the "by" query parameter
+const (
+	BreakdownReferrerHost = "host"
+	BreakdownUTMSource    = "utm_source"
+	BreakdownUTMMedium    = "utm_medium"
+	BreakdownUTMCampaign  = "utm_campaign"
+	BreakdownDevice       = "device"
+	BreakdownOS           = "os"
+	BreakdownBrowser      = "browser"
+	BreakdownCountry      = "country"
+	BreakdownRegion       = "region"
+	BreakdownCity         = "city"
+	BreakdownRule         = "rule"
+	BreakdownVariant      = "variant"
+)
+
+// Labels used in breakdowns for clicks without a regular value
+const (
+	ReferrerDirect  = "(direct)"
+	ReferrerUnknown = "(unknown)"
+	ReferrerSelf    = "(self)"
+	BreakdownNone   = "(none)"
+)
+
+// MaxTimeSeriesBuckets limits how many buckets a single time series may span
+const MaxTimeSeriesBuckets = 2000
+
+// Analytics validation errors
+var (
+	ErrInvalidInterval   = errors.New("interval must be one of hour, day or week")
+	ErrInvalidTimezone   = errors.New("tz must be a valid IANA time zone name")
+	ErrInvalidTimeRange  = errors.New("from must be before to")
+	ErrTimeRangeTooLarge = errors.New("time range contains too many buckets for the requested interval")
+	ErrInvalidBreakdown  = errors.New("unsupported breakdown dimension")
+)
+
+// TimeSeriesQuery describes the range and bucketing of a click time series
+type TimeSeriesQuery struct {
+	From        time.Time
+	To          time.Time
+	Interval    string
+	Location    *time.Location
+	IncludeBots bool
+}
+
+// TimeSeriesPoint is the number of clicks in a single bucket
+type TimeSeriesPoint struct {
+	Time   time.Time `json:"time"`
+	Clicks int64     `json:"clicks"`
+}
+
+// TimeSeriesResponse represents bucketed click counts for a URL
+type TimeSeriesResponse struct {
+	Alias    string            `json:"alias"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Interval string            `json:"interval"`
+	Timezone string            `json:"tz"`
+	Points   []TimeSeriesPoint `json:"points"`
+}
+
+// DailyVisitorSketch is the serialized HyperLogLog sketch of the distinct
+// visitors of a URL on a single UTC day. Sketch is empty for days without
+// visitors.
+type DailyVisitorSketch struct {
+	Day    time.Time
+	Sketch []byte
+}
+
+// UniqueVisitorsPoint is the approximate number of distinct visitors on a
+// single UTC day
+type UniqueVisitorsPoint struct {
+	Day            time.Time `json:"day"`
+	UniqueVisitors int64     `json:"unique_visitors"`
+}
+
+// UniqueVisitorsResponse represents daily unique visitor counts for a URL.
+// UniqueVisitors counts distinct visitors over the whole range, so it is
+// usually lower than the sum of the daily counts.
+type UniqueVisitorsResponse struct {
+	Alias          string                `json:"alias"`
+	From           time.Time             `json:"from"`
+	To             time.Time             `json:"to"`
+	UniqueVisitors int64                 `json:"unique_visitors"`
+	Points         []UniqueVisitorsPoint `json:"points"`
+}
+
+// VariantStats is the number of clicks sent to one variant of a URL
+type VariantStats struct {
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"`
+	Weight int    `json:"weight"` // zero for variants that were removed
+	Clicks int64  `json:"clicks"`
+}
+
+// VariantStatsResponse reports the clicks per variant of a URL. Variants
+// are listed in configured order, followed by removed variants that still
+// have clicks.
+type VariantStatsResponse struct {
+	Alias    string         `json:"alias"`
+	Clicks   int64          `json:"clicks"`
+	Variants []VariantStats `json:"variants"`
+}
+
+// BreakdownItem is the number of clicks sharing a single value of a dimension
+type BreakdownItem struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+// IntervalDuration returns the nominal length of a time series bucket
+func IntervalDuration(interval string) (time.Duration, error) {
+	switch interval {
+	case IntervalHour:
+		return time.Hour, nil
+	case IntervalDay:
+		return 24 * time.Hour, nil
+	case IntervalWeek:
+		return 7 * 24 * time.Hour, nil
+	default:
+		return 0, ErrInvalidInterval
+	}
+}
+
+// ValidateTimeSeriesQuery validates the range and bucketing of a time series
+func ValidateTimeSeriesQuery(q *TimeSeriesQuery) error {
+	step, err := IntervalDuration(q.Interval)
+	if err != nil {
+		return err
+	}
+
+	if !q.From.Before(q.To) {
+		return ErrInvalidTimeRange
+	}
+
+	if q.To.Sub(q.From)/step > MaxTimeSeriesBuckets {
+		return ErrTimeRangeTooLarge
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// API key scopes. A key can only be used on the endpoints its scopes cover.
+const (
+	ScopeLinksWrite = "links:write" // create, update and delete short URLs
+	ScopeLinksRead  = "links:read"  // read short URLs
+	ScopeStatsRead  = "stats:read"  // read click statistics
+)
+
+var apiKeyScopes = []string{ScopeLinksRead, ScopeLinksWrite, ScopeStatsRead}
+
+const (
+	// APIKeyPrefix starts every API key, so leaked keys are easy to spot
+	APIKeyPrefix = "usk_"
+	// APIKeyDisplayLength is the number of leading characters of a key kept to
+	// tell keys apart
+	APIKeyDisplayLength = 12
+
+	MaxAPIKeyNameLength = 64
+)
+
+// APIKey is a long-lived credential a user authenticates API calls with
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Username   string     `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // first characters of the key
+	KeyHash    string     `json:"-"`      // SHA-256 of the key, hex-encoded
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the key was granted a scope
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+// CreateAPIKeyResponse represents a newly created API key. The key itself is
+// only ever returned here.
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// API key errors
+var (
+	ErrInvalidAPIKeyName = errors.New("API key name must be 1-64 characters")
+	ErrInvalidScope      = errors.New("scopes must be one or more of links:read, links:write and stats:read")
+	ErrAPIKeyNotFound    = errors.New("API key not found")
+	ErrInvalidAPIKey     = errors.New("invalid or revoked API key")
+)
+
+// NormalizeAPIKeyName trims an API key name and validates it
+func NormalizeAPIKeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxAPIKeyNameLength {
+		return "", ErrInvalidAPIKeyName
+	}
+	for _, char := range name {
+		if char < ' ' || char == 0x7f {
+			return "", ErrInvalidAPIKeyName
+		}
+	}
+	return name, nil
+}
+
+// NormalizeScopes validates a list of API key scopes, returning them sorted
+// and without duplicates
+func NormalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(apiKeyScopes, scope) {
+			return nil, ErrInvalidScope
+		}
+		normalized = append(normalized, scope)
+	}
+
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"net/url"
+	"time"
+)
+
+// Visit describes a single request to a short link, as seen by the redirect handler
+type Visit struct {
+	Time           time.Time
+	Method         string
+	IP             string
+	UserAgent      string
+	Referrer       string
+	AcceptLanguage string
+	// Purpose is the value of the first prefetch hint header sent
+	// (Sec-Purpose, Purpose, X-Purpose or X-Moz)
+	Purpose string
+	Query   url.Values
+	// Client Hints: Sec-CH-UA-Platform and Sec-CH-UA-Mobile
+	PlatformHint string
+	MobileHint   string
+	// AssignedVariant is the variant remembered in the visitor's cookie
+	AssignedVariant string
+
+	// Filled in by AnalyticsService.EnrichVisit
+	Device   string
+	OS       string
+	Browser  string
+	Platform string // ios, android, desktop or empty
+	IsBot    bool
+	Unfurler bool // crawler fetching a preview card for a shared link
+	Country  string
+	Region   string
+	City     string
+	// VisitorHash is a keyed hash of IP and User-Agent, see ClickEvent
+	VisitorHash uint64
+
+	// MatchedRule is the targeting rule that picked the destination and
+	// Variant the variant the visitor was sent to, set by URLService.FollowURL
+	MatchedRule string
+	Variant     string
+}
+
+// ClickEvent represents a single recorded click on a short URL
+type ClickEvent struct {
+	ID             int64     `json:"id"`
+	URLID          int64     `json:"url_id"`
+	Alias          string    `json:"alias"`
+	ClickedAt      time.Time `json:"clicked_at"`
+	Referrer       string    `json:"referrer"`
+	UserAgent      string    `json:"user_agent"`
+	IPHash         string    `json:"ip_hash"`
+	AcceptLanguage string    `json:"accept_language"`
+	ReferrerHost   string    `json:"referrer_host"`
+	UTMSource      string    `json:"utm_source"`
+	UTMMedium      string    `json:"utm_medium"`
+	UTMCampaign    string    `json:"utm_campaign"`
+	DeviceClass    string    `json:"device_class"`
+	OSFamily       string    `json:"os_family"`
+	BrowserFamily  string    `json:"browser_family"`
+	Country        string    `json:"country"`
+	Region         string    `json:"region"`
+	City           string    `json:"city"`
+	IsBot          bool      `json:"is_bot"`
+	MatchedRule    string    `json:"matched_rule"`
+	Variant        string    `json:"variant"`
+
+	// VisitorHash identifies the visitor for unique visitor counting: a keyed
+	// hash of IP and User-Agent, truncated to 64 bits. It is not stored as is.
+	VisitorHash uint64 `json:"-"`
+}
+
+// ClickPipelineStats reports the state of the in-memory click buffer
+type ClickPipelineStats struct {
+	PendingEvents int64 `json:"pending_events"`
+	DroppedEvents int64 `json:"dropped_events"`
+	FailedEvents  int64 `json:"failed_events"`
+}
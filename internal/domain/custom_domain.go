@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// CustomDomain is a branded domain a user serves short URLs from, such as
+// go.acme.com. It can only be used once its owner verified it.
+type CustomDomain struct {
+	ID                int64      `json:"-"`
+	UserID            int64      `json:"-"`
+	Hostname          string     `json:"hostname"`
+	VerificationToken string     `json:"-"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// DomainVerificationLabel is prepended to a hostname to name the TXT record
+// that proves control of it
+const DomainVerificationLabel = "_shortener-verification"
+
+// IsVerified reports whether the owner proved control of the domain
+func (d *CustomDomain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// VerificationRecord returns the DNS TXT record that verifies the domain
+func (d *CustomDomain) VerificationRecord() DNSRecord {
+	return DNSRecord{
+		Type:  "TXT",
+		Name:  DomainVerificationLabel + "." + d.Hostname,
+		Value: "shortener-verification=" + d.VerificationToken,
+	}
+}
+
+// DNSRecord is a DNS record a user has to create
+type DNSRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// RegisterDomainRequest represents the request to add a custom domain
+type RegisterDomainRequest struct {
+	Hostname string `json:"hostname" binding:"required"`
+}
+
+// DomainResponse represents a custom domain and how to verify it
+type DomainResponse struct {
+	Hostname           string     `json:"hostname"`
+	Verified           bool       `json:"verified"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+	VerificationRecord DNSRecord  `json:"verification_record"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// Custom domain errors
+var (
+	ErrInvalidHostname    = errors.New("hostname must be a fully qualified domain name such as go.example.com")
+	ErrDomainNotFound     = errors.New("domain not found")
+	ErrDomainNotVerified  = errors.New("domain has not been verified")
+	ErrDomainTaken        = errors.New("domain is already verified by another user")
+	ErrDomainExists       = errors.New("domain is already registered")
+	ErrDomainInUse        = errors.New("domain still has short URLs; deleted ones must be purged first")
+	ErrVerificationFailed = errors.New("verification TXT record not found")
+	ErrReservedHostname   = errors.New("hostname is the shortener's own domain")
+)
+
+// NormalizeHostname lower-cases a hostname and strips a port and trailing dot
+func NormalizeHostname(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.LastIndexByte(host, ':'); i != -1 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// ValidateHostname validates a normalized custom domain hostname: at least two
+// labels of letters, digits and hyphens, not starting or ending with a hyphen
+func ValidateHostname(hostname string) error {
+	if len(hostname) > 253 {
+		return ErrInvalidHostname
+	}
+
+	labels := strings.Split(hostname, ".")
+	if len(labels) < 2 {
+		return ErrInvalidHostname
+	}
+
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return ErrInvalidHostname
+		}
+		for _, char := range label {
+			if !((char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') || char == '-') {
+				return ErrInvalidHostname
+			}
+		}
+	}
+
+	// The top-level domain is never all digits, which also rules out IPv4
+	// addresses
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return ErrInvalidHostname
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Query policies, deciding what happens to the query string of a request for
+// a short link
+const (
+	// QueryPolicyDrop ignores the query string. This is the default.
+	QueryPolicyDrop = "drop"
+	// QueryPolicyAppend adds the parameters to the destination's query,
+	// keeping both values when a parameter is on both
+	QueryPolicyAppend = "append"
+	// QueryPolicyMerge adds the parameters the destination does not already
+	// have, so the destination's own values take precedence
+	QueryPolicyMerge = "merge"
+	// QueryPolicyOverride adds the parameters, replacing the destination's
+	// values of the same parameters
+	QueryPolicyOverride = "override"
+)
+
+var ErrInvalidQueryPolicy = errors.New("query policy must be one of drop, append, merge or override")
+
+// reservedQueryParams control the shortener itself and are never passed on
+var reservedQueryParams = map[string]bool{
+	"preview": true,
+	"confirm": true,
+}
+
+// IsReservedQueryParam reports whether a query parameter controls the
+// shortener itself rather than being meant for the destination
+func IsReservedQueryParam(key string) bool {
+	return reservedQueryParams[key]
+}
+
+// ValidateQueryPolicy validates the query policy of a URL
+func ValidateQueryPolicy(policy string) error {
+	switch policy {
+	case QueryPolicyDrop, QueryPolicyAppend, QueryPolicyMerge, QueryPolicyOverride:
+		return nil
+	default:
+		return ErrInvalidQueryPolicy
+	}
+}
+
+// ApplyQueryPolicy passes the query parameters of a short link request on to
+// a destination according to policy. The destination's own parameters keep
+// their order and encoding, and its fragment is preserved.
+func ApplyQueryPolicy(destination string, incoming url.Values, policy string) string {
+	if policy == "" || policy == QueryPolicyDrop || len(incoming) == 0 {
+		return destination
+	}
+
+	forwarded := make(url.Values, len(incoming))
+	for key, values := range incoming {
+		if !IsReservedQueryParam(key) {
+			forwarded[key] = values
+		}
+	}
+	if len(forwarded) == 0 {
+		return destination
+	}
+
+	target, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	var pairs []string
+	for _, pair := range strings.Split(target.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		_, incomingKey := forwarded[queryKey(pair)]
+		switch {
+		case policy == QueryPolicyMerge && incomingKey:
+			delete(forwarded, queryKey(pair))
+		case policy == QueryPolicyOverride && incomingKey:
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if encoded := forwarded.Encode(); encoded != "" {
+		pairs = append(pairs, encoded)
+	}
+	target.RawQuery = strings.Join(pairs, "&")
+
+	return target.String()
+}
+
+// queryKey returns the unescaped key of a raw key=value query pair
+func queryKey(pair string) string {
+	key, _, _ := strings.Cut(pair, "=")
+	if unescaped, err := url.QueryUnescape(key); err == nil {
+		return unescaped
+	}
+	return key
+}
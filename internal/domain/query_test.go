@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestApplyQueryPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		incoming    string
+		policy      string
+		want        string
+	}{
+		// Drop
+		{
+			name:        "drop ignores the query",
+			destination: "https://example.com/page?a=1",
+			incoming:    "a=2&b=3",
+			policy:      QueryPolicyDrop,
+			want:        "https://example.com/page?a=1",
+		},
+		{
+			name:        "empty policy drops",
+			destination: "https://example.com/page",
+			incoming:    "b=3",
+			policy:      "",
+			want:        "https://example.com/page",
+		},
+
+		// Append
+		{
+			name:        "append to a destination without a query",
+			destination: "https://example.com/page",
+			incoming:    "utm_source=news",
+			policy:      QueryPolicyAppend,
+			want:        "https://example.com/page?utm_source=news",
+		},
+		{
+			name:        "append keeps both values of a shared parameter",
+			destination: "https://example.com/page?a=1&b=2",
+			incoming:    "a=9&c=3",
+			policy:      QueryPolicyAppend,
+			want:        "https://example.com/page?a=1&b=2&a=9&c=3",
+		},
+		{
+			name:        "append keeps repeated incoming values",
+			destination: "https://example.com/page",
+			incoming:    "tag=x&tag=y",
+			policy:      QueryPolicyAppend,
+			want:        "https://example.com/page?tag=x&tag=y",
+		},
+
+		// Merge
+		{
+			name:        "merge keeps the destination's value",
+			destination: "https://example.com/page?a=1&b=2",
+			incoming:    "a=9&c=3",
+			policy:      QueryPolicyMerge,
+			want:        "https://example.com/page?a=1&b=2&c=3",
+		},
+		{
+			name:        "merge matches escaped destination keys",
+			destination: "https://example.com/page?my%20key=1",
+			incoming:    "my+key=9",
+			policy:      QueryPolicyMerge,
+			want:        "https://example.com/page?my%20key=1",
+		},
+
+		// Override
+		{
+			name:        "override replaces the destination's value",
+			destination: "https://example.com/page?a=1&b=2",
+			incoming:    "a=9&c=3",
+			policy:      QueryPolicyOverride,
+			want:        "https://example.com/page?b=2&a=9&c=3",
+		},
+		{
+			name:        "override replaces every destination value of a parameter",
+			destination: "https://example.com/page?a=1&a=2&b=2",
+			incoming:    "a=9",
+			policy:      QueryPolicyOverride,
+			want:        "https://example.com/page?b=2&a=9",
+		},
+
+		// Destination details are preserved
+		{
+			name:        "fragment is kept after the query",
+			destination: "https://example.com/page?a=1#section-2",
+			incoming:    "b=2",
+			policy:      QueryPolicyAppend,
+			want:        "https://example.com/page?a=1&b=2#section-2",
+		},
+		{
+			name:        "fragment without a query",
+			destination: "https://example.com/app#/route",
+			incoming:    "b=2",
+			policy:      QueryPolicyOverride,
+			want:        "https://example.com/app?b=2#/route",
+		},
+		{
+			name:        "destination encoding is left alone",
+			destination: "https://example.com/search?q=a%2Bb&empty=&flag",
+			incoming:    "ref=x",
+			policy:      QueryPolicyAppend,
+			want:        "https://example.com/search?q=a%2Bb&empty=&flag&ref=x",
+		},
+		{
+			name:        "incoming values are encoded",
+			destination: "https://example.com/page",
+			incoming:    "q=a%26b+c",
+			policy:      QueryPolicyAppend,
+			want:        "https://example.com/page?q=a%26b+c",
+		},
+
+		// Reserved parameters
+		{
+			name:        "reserved parameters are not passed on",
+			destination: "https://example.com/page",
+			incoming:    "preview=1&confirm=1&a=1",
+			policy:      QueryPolicyAppend,
+			want:        "https://example.com/page?a=1",
+		},
+		{
+			name:        "only reserved parameters leave the destination unchanged",
+			destination: "https://example.com/page?x=1#top",
+			incoming:    "confirm=1",
+			policy:      QueryPolicyOverride,
+			want:        "https://example.com/page?x=1#top",
+		},
+		{
+			name:        "no incoming parameters",
+			destination: "https://example.com/page?x=1",
+			incoming:    "",
+			policy:      QueryPolicyMerge,
+			want:        "https://example.com/page?x=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incoming, err := url.ParseQuery(tt.incoming)
+			if err != nil {
+				t.Fatalf("invalid incoming query %q: %v", tt.incoming, err)
+			}
+
+			if got := ApplyQueryPolicy(tt.destination, incoming, tt.policy); got != tt.want {
+				t.Errorf("ApplyQueryPolicy(%q, %q, %q)\n got  %s\n want %s", tt.destination, tt.incoming, tt.policy, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateQueryPolicy(t *testing.T) {
+	for _, policy := range []string{QueryPolicyDrop, QueryPolicyAppend, QueryPolicyMerge, QueryPolicyOverride} {
+		if err := ValidateQueryPolicy(policy); err != nil {
+			t.Errorf("ValidateQueryPolicy(%q) error = %v", policy, err)
+		}
+	}
+	for _, policy := range []string{"", "replace", "APPEND"} {
+		if err := ValidateQueryPolicy(policy); err != ErrInvalidQueryPolicy {
+			t.Errorf("ValidateQueryPolicy(%q) error = %v, want ErrInvalidQueryPolicy", policy, err)
+		}
+	}
+}
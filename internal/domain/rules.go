@@ -0,0 +1,230 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxGeoRules limits how many geo rules a single URL may have
+const MaxGeoRules = 50
+
+// Limits on the variants of a URL
+const (
+	MaxVariants          = 10
+	MaxVariantWeight     = 1000
+	MaxVariantNameLength = 32
+)
+
+// Platforms that device rules can target
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformDesktop = "desktop"
+)
+
+// Targeting validation errors
+var (
+	ErrInvalidGeoRule      = errors.New("each geo rule needs a url and one or more ISO 3166-1 alpha-2 country codes")
+	ErrTooManyGeoRules     = fmt.Errorf("a URL may have at most %d geo rules", MaxGeoRules)
+	ErrInvalidDeviceRule   = errors.New("each device rule needs a url and a platform of ios, android or desktop")
+	ErrDuplicateDeviceRule = errors.New("a URL may have only one device rule per platform")
+	ErrInvalidVariant      = fmt.Errorf("each variant needs a url, a weight between 1 and %d and a name of up to %d letters, digits, hyphens or underscores", MaxVariantWeight, MaxVariantNameLength)
+	ErrInvalidVariantCount = fmt.Errorf("a URL needs between 2 and %d variants, or none", MaxVariants)
+	ErrDuplicateVariant    = errors.New("variant names must be unique")
+)
+
+// GeoRule sends visitors from any of its countries to its own destination
+// instead of the URL's original_url
+type GeoRule struct {
+	Countries []string `json:"country"` // ISO 3166-1 alpha-2 codes, e.g. ["DE", "AT"]
+	URL       string   `json:"url"`
+}
+
+// DeviceRule sends visitors on a platform to its own destination instead of
+// the URL's original_url
+type DeviceRule struct {
+	Platform string `json:"platform"` // ios, android or desktop
+	URL      string `json:"url"`
+}
+
+// Variant is one of several weighted destinations of a URL. Each visitor is
+// sent to one variant, with a chance proportional to its weight.
+type Variant struct {
+	Name   string `json:"name"` // defaults to A, B, C, ... by position
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// ValidateGeoRules validates an ordered list of geo rules, normalizing their
+// country codes to upper case
+func ValidateGeoRules(rules []GeoRule) error {
+	if len(rules) > MaxGeoRules {
+		return ErrTooManyGeoRules
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if len(rule.Countries) == 0 {
+			return fmt.Errorf("geo rule %d: %w", i+1, ErrInvalidGeoRule)
+		}
+
+		for j, country := range rule.Countries {
+			country = strings.ToUpper(strings.TrimSpace(country))
+			if !isCountryCode(country) {
+				return fmt.Errorf("geo rule %d: %w", i+1, ErrInvalidGeoRule)
+			}
+			rule.Countries[j] = country
+		}
+
+		if err := ValidateURL(rule.URL); err != nil {
+			return fmt.Errorf("geo rule %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateDeviceRules validates a list of device rules, normalizing their
+// platforms to lower case
+func ValidateDeviceRules(rules []DeviceRule) error {
+	seen := make(map[string]bool, len(rules))
+
+	for i := range rules {
+		rule := &rules[i]
+		rule.Platform = strings.ToLower(strings.TrimSpace(rule.Platform))
+		switch rule.Platform {
+		case PlatformIOS, PlatformAndroid, PlatformDesktop:
+		default:
+			return fmt.Errorf("device rule %d: %w", i+1, ErrInvalidDeviceRule)
+		}
+
+		if seen[rule.Platform] {
+			return fmt.Errorf("device rule %d: %w", i+1, ErrDuplicateDeviceRule)
+		}
+		seen[rule.Platform] = true
+
+		if err := ValidateURL(rule.URL); err != nil {
+			return fmt.Errorf("device rule %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateVariants validates a list of variants, naming unnamed ones after
+// their position
+func ValidateVariants(variants []Variant) error {
+	if len(variants) == 1 || len(variants) > MaxVariants {
+		return ErrInvalidVariantCount
+	}
+
+	seen := make(map[string]bool, len(variants))
+	for i := range variants {
+		variant := &variants[i]
+		variant.Name = strings.TrimSpace(variant.Name)
+		if variant.Name == "" {
+			variant.Name = string(rune('A' + i))
+		}
+
+		if !isVariantName(variant.Name) || variant.Weight < 1 || variant.Weight > MaxVariantWeight {
+			return fmt.Errorf("variant %d: %w", i+1, ErrInvalidVariant)
+		}
+
+		if seen[variant.Name] {
+			return fmt.Errorf("variant %d: %w", i+1, ErrDuplicateVariant)
+		}
+		seen[variant.Name] = true
+
+		if err := ValidateURL(variant.URL); err != nil {
+			return fmt.Errorf("variant %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Destination picks where to send a visitor: the device rule for their
+// platform, else the first geo rule listing their country, else original_url.
+// It also returns the name of the matched rule, such as "device:ios" or
+// "geo:2", or an empty string when original_url is used. Unknown platforms
+// and countries match no rule.
+func (u *URL) Destination(platform, country string) (string, string) {
+	if platform != "" {
+		for _, rule := range u.DeviceRules {
+			if rule.Platform == platform {
+				return rule.URL, "device:" + platform
+			}
+		}
+	}
+
+	if country != "" {
+		for i, rule := range u.GeoRules {
+			for _, c := range rule.Countries {
+				if c == country {
+					return rule.URL, "geo:" + strconv.Itoa(i+1)
+				}
+			}
+		}
+	}
+
+	return u.OriginalURL, ""
+}
+
+// PickVariant picks the variant a visitor is sent to. A visitor who was
+// already assigned a variant that still exists keeps it. Others are placed by
+// their visitor hash, so the same visitor lands on the same variant even
+// without a cookie. It returns false for URLs without variants.
+func (u *URL) PickVariant(assigned string, visitorHash uint64) (Variant, bool) {
+	if len(u.Variants) == 0 {
+		return Variant{}, false
+	}
+
+	total := 0
+	for _, variant := range u.Variants {
+		if variant.Name == assigned {
+			return variant, true
+		}
+		total += variant.Weight
+	}
+
+	// Mix in the URL ID, so a visitor's variants on different links are
+	// independent of each other
+	x := visitorHash ^ uint64(u.ID)*0x9e3779b97f4a7c15
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+
+	bucket := int(x % uint64(total))
+	for _, variant := range u.Variants {
+		if bucket < variant.Weight {
+			return variant, true
+		}
+		bucket -= variant.Weight
+	}
+
+	return u.Variants[len(u.Variants)-1], true
+}
+
+// isCountryCode reports whether s looks like an upper-case ISO 3166-1
+// alpha-2 code
+func isCountryCode(s string) bool {
+	return len(s) == 2 && s[0] >= 'A' && s[0] <= 'Z' && s[1] >= 'A' && s[1] <= 'Z'
+}
+
+// isVariantName reports whether name may name a variant
+func isVariantName(name string) bool {
+	if len(name) > MaxVariantNameLength {
+		return false
+	}
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '-' || char == '_') {
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+// visitorHashes returns n uniformly distributed visitor hashes from a
+// SplitMix64 sequence, so runs are reproducible
+func visitorHashes(n int) []uint64 {
+	out := make([]uint64, n)
+	for i := range out {
+		z := uint64(i+1) * 0x9e3779b97f4a7c15
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		out[i] = z ^ (z >> 31)
+	}
+	return out
+}
+
+func TestDestination(t *testing.T) {
+	url := &URL{
+		OriginalURL: "https://example.com/default",
+		DeviceRules: []DeviceRule{
+			{Platform: PlatformIOS, URL: "https://example.com/ios"},
+			{Platform: PlatformAndroid, URL: "https://example.com/android"},
+		},
+		GeoRules: []GeoRule{
+			{Countries: []string{"DE", "AT"}, URL: "https://example.com/dach"},
+			{Countries: []string{"FR", "DE"}, URL: "https://example.com/fr"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		url      *URL
+		platform string
+		country  string
+		wantURL  string
+		wantRule string
+	}{
+		{name: "device rule", url: url, platform: PlatformIOS, country: "US", wantURL: "https://example.com/ios", wantRule: "device:ios"},
+		{name: "device rule beats geo rule", url: url, platform: PlatformAndroid, country: "DE", wantURL: "https://example.com/android", wantRule: "device:android"},
+		{name: "geo rule without a device rule for the platform", url: url, platform: PlatformDesktop, country: "AT", wantURL: "https://example.com/dach", wantRule: "geo:1"},
+		{name: "first matching geo rule wins", url: url, country: "DE", wantURL: "https://example.com/dach", wantRule: "geo:1"},
+		{name: "later geo rule", url: url, country: "FR", wantURL: "https://example.com/fr", wantRule: "geo:2"},
+		{name: "no matching rule", url: url, platform: PlatformDesktop, country: "US", wantURL: "https://example.com/default"},
+		{name: "unknown platform and country", url: url, wantURL: "https://example.com/default"},
+		{name: "no rules", url: &URL{OriginalURL: "https://example.com/plain"}, platform: PlatformIOS, country: "DE", wantURL: "https://example.com/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, gotRule := tt.url.Destination(tt.platform, tt.country)
+			if gotURL != tt.wantURL || gotRule != tt.wantRule {
+				t.Errorf("Destination(%q, %q) = %q, %q, want %q, %q", tt.platform, tt.country, gotURL, gotRule, tt.wantURL, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestPickVariantDistribution(t *testing.T) {
+	const visitors = 100000
+	// Allowed deviation from the expected share, about six standard errors
+	const tolerance = 0.01
+
+	tests := []struct {
+		name     string
+		variants []Variant
+	}{
+		{name: "even split", variants: []Variant{{Name: "A", Weight: 1}, {Name: "B", Weight: 1}}},
+		{name: "uneven split", variants: []Variant{{Name: "A", Weight: 1}, {Name: "B", Weight: 3}}},
+		{name: "three ways", variants: []Variant{{Name: "A", Weight: 20}, {Name: "B", Weight: 30}, {Name: "C", Weight: 50}}},
+		{name: "rare variant", variants: []Variant{{Name: "A", Weight: 999}, {Name: "B", Weight: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := &URL{ID: 42, Variants: tt.variants}
+
+			counts := make(map[string]int)
+			for _, h := range visitorHashes(visitors) {
+				variant, ok := url.PickVariant("", h)
+				if !ok {
+					t.Fatal("PickVariant() found no variant")
+				}
+				counts[variant.Name]++
+			}
+
+			total := 0
+			for _, variant := range tt.variants {
+				total += variant.Weight
+			}
+			for _, variant := range tt.variants {
+				want := float64(variant.Weight) / float64(total)
+				got := float64(counts[variant.Name]) / visitors
+				if math.Abs(got-want) > tolerance {
+					t.Errorf("variant %s got %.2f%% of visitors, want %.2f%%", variant.Name, 100*got, 100*want)
+				}
+			}
+		})
+	}
+}
+
+func TestPickVariantStickiness(t *testing.T) {
+	variants := []Variant{{Name: "A", Weight: 1}, {Name: "B", Weight: 1}, {Name: "C", Weight: 2}}
+	url := &URL{ID: 7, Variants: variants}
+	hash := visitorHashes(1)[0]
+
+	byHash, _ := url.PickVariant("", hash)
+
+	tests := []struct {
+		name     string
+		url      *URL
+		assigned string
+		want     string
+		wantOK   bool
+	}{
+		{name: "same hash, same variant", url: url, want: byHash.Name, wantOK: true},
+		{name: "assigned variant is kept", url: url, assigned: "B", want: "B", wantOK: true},
+		{name: "assigned variant is kept regardless of weight", url: url, assigned: "A", want: "A", wantOK: true},
+		{name: "removed variant falls back to the hash", url: url, assigned: "D", want: byHash.Name, wantOK: true},
+		{name: "weights changed", url: &URL{ID: 7, Variants: []Variant{{Name: "A", Weight: 5}, {Name: "B", Weight: 1}}}, assigned: "B", want: "B", wantOK: true},
+		{name: "no variants", url: &URL{ID: 7}, assigned: "A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				got, ok := tt.url.PickVariant(tt.assigned, hash)
+				if ok != tt.wantOK || got.Name != tt.want {
+					t.Fatalf("PickVariant(%q) = %q, %v, want %q, %v", tt.assigned, got.Name, ok, tt.want, tt.wantOK)
+				}
+			}
+		})
+	}
+}
+
+func TestPickVariantIndependentAcrossURLs(t *testing.T) {
+	variants := []Variant{{Name: "A", Weight: 1}, {Name: "B", Weight: 1}}
+	first := &URL{ID: 1, Variants: variants}
+	second := &URL{ID: 2, Variants: variants}
+
+	const visitors = 10000
+	same := 0
+	for _, h := range visitorHashes(visitors) {
+		a, _ := first.PickVariant("", h)
+		b, _ := second.PickVariant("", h)
+		if a.Name == b.Name {
+			same++
+		}
+	}
+
+	// Independent 50/50 splits agree for about half the visitors
+	if share := float64(same) / visitors; math.Abs(share-0.5) > 0.03 {
+		t.Errorf("%.1f%% of visitors got the same variant on two links, want about 50%%", 100*share)
+	}
+}
@@ -2,26 +2,149 @@ package domain
 
 import (
 	"errors"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // URL represents a shortened URL entity
 type URL struct {
-	ID          int64     `json:"id"`
-	Alias       string    `json:"alias"`
-	OriginalURL string    `json:"original_url"`
-	UserID      int64     `json:"user_id"`
-	ClickCount  int64     `json:"click_count"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             int64        `json:"id"`
+	Alias          string       `json:"alias"`
+	DomainID       int64        `json:"-"`                // zero for URLs served from BASE_URL
+	Domain         string       `json:"domain,omitempty"` // custom domain hostname, if any
+	OriginalURL    string       `json:"original_url"`
+	UserID         int64        `json:"user_id"`                // creator
+	WorkspaceID    int64        `json:"workspace_id,omitempty"` // zero for personal URLs of the creator
+	ClickCount     int64        `json:"click_count"`
+	BotClickCount  int64        `json:"bot_click_count"` // crawlers, link previewers and prefetches
+	ClaimedClicks  int64        `json:"-"`               // click cap counter, see ClaimClick
+	UniqueVisitors int64        `json:"unique_visitors"` // approximate, from a HyperLogLog sketch
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	DeletedAt      *time.Time   `json:"deleted_at,omitempty"`
+	RedirectType   int          `json:"redirect_type"`   // 301, 302, 307 or 308
+	PasswordHash   string       `json:"-"`               // bcrypt hash, empty if the link is not protected
+	Title          string       `json:"title,omitempty"` // title of the destination page, if known
+	ForcePreview   bool         `json:"force_preview"`   // always show the preview page before redirecting
+	GeoRules       []GeoRule    `json:"geo_rules,omitempty"`
+	DeviceRules    []DeviceRule `json:"device_rules,omitempty"`
+	Variants       []Variant    `json:"variants,omitempty"`
+	QueryPolicy    string       `json:"query_policy"` // drop, append, merge or override
+
+	// Social preview overrides, shown by crawlers that unfurl shared links
+	OGTitle       string `json:"og_title,omitempty"`
+	OGDescription string `json:"og_description,omitempty"`
+	OGImage       string `json:"og_image,omitempty"`
+
+	// Optional lifetime. Once the link expires, visitors are sent to
+	// ExpiredRedirectURL, or shown an expiry page if it is empty.
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	MaxClicks          *int64     `json:"max_clicks,omitempty"`
+	ExpiredRedirectURL string     `json:"expired_redirect_url,omitempty"`
+}
+
+// IsExpired reports whether the URL is past its expiry time or click cap
+func (u *URL) IsExpired(now time.Time) bool {
+	if u.ExpiresAt != nil && !now.Before(*u.ExpiresAt) {
+		return true
+	}
+	if u.MaxClicks != nil && u.CappedClicks() >= *u.MaxClicks {
+		return true
+	}
+	return false
+}
+
+// CappedClicks returns the number of clicks counted against the click cap.
+// Clicks on capped links are claimed before their events are written, so this
+// can be ahead of ClickCount.
+func (u *URL) CappedClicks() int64 {
+	return max(u.ClickCount, u.ClaimedClicks)
 }
 
 // ShortenRequest represents the request to create a short URL
 type ShortenRequest struct {
 	URL   string `json:"url" binding:"required"`
 	Alias string `json:"alias"`
+	// Domain is a verified custom domain of the user to serve the short URL
+	// from. Aliases are unique per domain. It defaults to BASE_URL.
+	Domain string `json:"domain"`
+	// WorkspaceID creates the short URL in a workspace the user is an editor
+	// or owner of, instead of as a personal URL
+	WorkspaceID        int64      `json:"workspace_id"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	MaxClicks          *int64     `json:"max_clicks"`
+	ExpiredRedirectURL string     `json:"expired_redirect_url"`
+	// RedirectType is the HTTP status used to redirect (301, 302, 307 or
+	// 308). It defaults to the owner's default redirect type.
+	RedirectType int `json:"redirect_type"`
+	// Password protects the link: visitors must enter it before being
+	// redirected
+	Password string `json:"password"`
+	// Title of the destination page, shown on the preview page
+	Title string `json:"title"`
+	// ForcePreview always shows the preview page before redirecting, for
+	// destinations visitors should check first
+	ForcePreview bool `json:"force_preview"`
+	// GeoRules send visitors from the listed countries to other destinations.
+	// They are evaluated in order; the first match wins.
+	GeoRules []GeoRule `json:"geo_rules"`
+	// DeviceRules send visitors on iOS, Android or desktop to other
+	// destinations. They take precedence over geo rules.
+	DeviceRules []DeviceRule `json:"device_rules"`
+	// Variants split visitors between weighted destinations, e.g. 70/30.
+	// Visitors matching a device or geo rule follow the rule instead.
+	Variants []Variant `json:"variants"`
+	// QueryPolicy decides whether the query string of a request for the
+	// short link is passed on to the destination: drop (default), append,
+	// merge (destination values win) or override (request values win)
+	QueryPolicy string `json:"query_policy"`
+	// UTM parameters are set on the destination URL, replacing any it already
+	// has. UTMPreset names a saved preset to start from; fields set in UTM
+	// take precedence over the preset.
+	UTM       *UTMParams `json:"utm"`
+	UTMPreset string     `json:"utm_preset"`
+	// Open Graph and Twitter Card overrides. When set, crawlers that unfurl
+	// shared links get a page with these instead of the redirect.
+	OGTitle       string `json:"og_title"`
+	OGDescription string `json:"og_description"`
+	OGImage       string `json:"og_image"`
+}
+
+// UpdateURLRequest represents the request to update an existing short URL.
+// Fields left out of the request body are not changed.
+type UpdateURLRequest struct {
+	// WorkspaceID moves the URL into a workspace the user is an editor or
+	// owner of. Zero moves it back to the personal URLs of its creator. Only
+	// the creator and owners of the current workspace can move a URL out of
+	// it.
+	WorkspaceID        *int64     `json:"workspace_id"`
+	OriginalURL        *string    `json:"original_url"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	MaxClicks          *int64     `json:"max_clicks"`
+	ExpiredRedirectURL *string    `json:"expired_redirect_url"`
+	// ClearExpiresAt removes the expiration time, ClearMaxClicks the click
+	// limit. They cannot be combined with setting the same field.
+	ClearExpiresAt bool `json:"clear_expires_at"`
+	ClearMaxClicks bool `json:"clear_max_clicks"`
+	RedirectType   *int `json:"redirect_type"`
+	// Password sets a new password, or removes protection when empty
+	Password     *string `json:"password"`
+	Title        *string `json:"title"`
+	ForcePreview *bool   `json:"force_preview"`
+	// GeoRules replaces all geo rules; an empty list removes them
+	GeoRules *[]GeoRule `json:"geo_rules"`
+	// DeviceRules replaces all device rules; an empty list removes them
+	DeviceRules *[]DeviceRule `json:"device_rules"`
+	// Variants replaces all variants; an empty list removes them
+	Variants    *[]Variant `json:"variants"`
+	QueryPolicy *string    `json:"query_policy"`
+	// Open Graph overrides; an empty string removes one
+	OGTitle       *string `json:"og_title"`
+	OGDescription *string `json:"og_description"`
+	OGImage       *string `json:"og_image"`
 }
 
 // ShortenResponse represents the response after creating a short URL
@@ -33,12 +156,31 @@ type ShortenResponse struct {
 
 // URLInfoResponse represents detailed URL information
 type URLInfoResponse struct {
-	Alias       string    `json:"alias"`
-	OriginalURL string    `json:"original_url"`
-	UserID      int64     `json:"user_id"`
-	ClickCount  int64     `json:"click_count"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Alias              string       `json:"alias"`
+	Domain             string       `json:"domain,omitempty"`
+	ShortURL           string       `json:"short_url"`
+	OriginalURL        string       `json:"original_url"`
+	UserID             int64        `json:"user_id"`
+	WorkspaceID        int64        `json:"workspace_id,omitempty"`
+	ClickCount         int64        `json:"click_count"`
+	BotClickCount      int64        `json:"bot_click_count"`
+	UniqueVisitors     int64        `json:"unique_visitors"`
+	RedirectType       int          `json:"redirect_type"`
+	PasswordProtected  bool         `json:"password_protected"`
+	Title              string       `json:"title,omitempty"`
+	ForcePreview       bool         `json:"force_preview"`
+	GeoRules           []GeoRule    `json:"geo_rules,omitempty"`
+	DeviceRules        []DeviceRule `json:"device_rules,omitempty"`
+	Variants           []Variant    `json:"variants,omitempty"`
+	QueryPolicy        string       `json:"query_policy"`
+	OGTitle            string       `json:"og_title,omitempty"`
+	OGDescription      string       `json:"og_description,omitempty"`
+	OGImage            string       `json:"og_image,omitempty"`
+	ExpiresAt          *time.Time   `json:"expires_at,omitempty"`
+	MaxClicks          *int64       `json:"max_clicks,omitempty"`
+	ExpiredRedirectURL string       `json:"expired_redirect_url,omitempty"`
+	CreatedAt          time.Time    `json:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at"`
 }
 
 // Validation errors
@@ -48,11 +190,31 @@ var (
 	ErrInvalidAlias = errors.New("alias must contain only alphanumeric characters, hyphens, and underscores")
 	ErrAliasTooLong = errors.New("alias must not exceed 16 characters")
 	ErrPrivateURL   = errors.New("private IP addresses and localhost are not allowed")
+
+	ErrExpiryInPast     = errors.New("expiration time must be in the future")
+	ErrInvalidMaxClicks = errors.New("max clicks must be greater than zero")
+	ErrMaxClicksReached = errors.New("max clicks must be greater than the current click count")
+	ErrSetAndClear      = errors.New("a field cannot be both set and cleared")
+
+	ErrInvalidRedirectType  = errors.New("redirect type must be one of 301, 302, 307 or 308")
+	ErrInvalidLinkPassword  = errors.New("link password must be 4-72 characters")
+	ErrTitleTooLong         = errors.New("title must not exceed 200 characters")
+	ErrOGDescriptionTooLong = errors.New("og_description must not exceed 500 characters")
 )
 
+// DefaultRedirectType is used by users who have not chosen a default
+const DefaultRedirectType = http.StatusFound
+
 const (
 	MaxURLLength   = 2048
 	MaxAliasLength = 16
+
+	// Link passwords are hashed with bcrypt, which only uses the first 72 bytes
+	MinLinkPasswordLength = 4
+	MaxLinkPasswordLength = 72
+
+	MaxTitleLength         = 200
+	MaxOGDescriptionLength = 500
 )
 
 // ValidateURL validates the original URL
@@ -102,7 +264,8 @@ func ValidateAlias(alias string) error {
 		return ErrAliasTooLong
 	}
 
-	// Check characters (alphanumeric, hyphen, underscore only)
+	// Check characters (alphanumeric, hyphen, underscore only). Anything
+	// else, such as the "+" preview suffix, could make the alias unreachable.
 	for _, char := range alias {
 		if !((char >= 'a' && char <= 'z') ||
 			(char >= 'A' && char <= 'Z') ||
@@ -114,3 +277,75 @@ func ValidateAlias(alias string) error {
 
 	return nil
 }
+
+// ValidateExpiration validates the optional lifetime settings of a URL
+func ValidateExpiration(expiresAt *time.Time, maxClicks *int64, expiredRedirectURL string) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ErrExpiryInPast
+	}
+
+	if maxClicks != nil && *maxClicks <= 0 {
+		return ErrInvalidMaxClicks
+	}
+
+	if expiredRedirectURL != "" {
+		return ValidateURL(expiredRedirectURL)
+	}
+
+	return nil
+}
+
+// ValidateRedirectType validates the HTTP status used to redirect to a URL
+func ValidateRedirectType(redirectType int) error {
+	switch redirectType {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return nil
+	default:
+		return ErrInvalidRedirectType
+	}
+}
+
+// IsPermanentRedirect reports whether browsers may cache a redirect type
+// without asking again (301 and 308)
+func IsPermanentRedirect(redirectType int) bool {
+	return redirectType == http.StatusMovedPermanently || redirectType == http.StatusPermanentRedirect
+}
+
+// ValidateLinkPassword validates the password protecting a URL
+func ValidateLinkPassword(password string) error {
+	if len(password) < MinLinkPasswordLength || len(password) > MaxLinkPasswordLength {
+		return ErrInvalidLinkPassword
+	}
+	return nil
+}
+
+// ValidateTitle validates the destination page title of a URL
+func ValidateTitle(title string) error {
+	if utf8.RuneCountInString(title) > MaxTitleLength {
+		return ErrTitleTooLong
+	}
+	return nil
+}
+
+// ValidateOpenGraph validates the social preview overrides of a URL. The
+// title has the same limit as the page title.
+func ValidateOpenGraph(title, description, image string) error {
+	if err := ValidateTitle(title); err != nil {
+		return err
+	}
+
+	if utf8.RuneCountInString(description) > MaxOGDescriptionLength {
+		return ErrOGDescriptionTooLong
+	}
+
+	if image != "" {
+		return ValidateURL(image)
+	}
+
+	return nil
+}
+
+// HasOpenGraph reports whether any social preview override is set
+func (u *URL) HasOpenGraph() bool {
+	return u.OGTitle != "" || u.OGDescription != "" || u.OGImage != ""
+}
@@ -7,11 +7,13 @@ import (
 )
 
 type User struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	Password  string    `json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                  int64     `json:"id"`
+	Username            string    `json:"username"`
+	Password            string    `json:"-"`
+	Role                string    `json:"role"` // user, moderator or admin
+	DefaultRedirectType int       `json:"default_redirect_type"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 type RegisterRequest struct {
@@ -25,10 +27,43 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// UserSettings represents the per-user defaults applied to new short URLs
+type UserSettings struct {
+	DefaultRedirectType int `json:"default_redirect_type"`
+}
+
+// UpdateUserSettingsRequest represents the request to change user settings.
+// Fields left out of the request body are not changed.
+type UpdateUserSettingsRequest struct {
+	DefaultRedirectType *int `json:"default_redirect_type"`
+}
+
 type AuthResponse struct {
 	Token    string `json:"token"`
 	Username string `json:"username"`
 	UserID   int64  `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+// UpdateUserRoleRequest represents the request to change the role of a user
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// User roles, from least to most privileged. Moderators can review every
+// short URL; admins can also purge URLs, inspect the click pipeline and
+// manage user roles.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// roleRanks orders the user roles by privilege
+var roleRanks = map[string]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
 }
 
 var (
@@ -37,6 +72,8 @@ var (
 	ErrUserNotFound          = errors.New("user not found")
 	ErrInvalidCredentials    = errors.New("invalid username or password")
 	ErrUsernameAlreadyExists = errors.New("username already exists")
+	ErrInvalidRole           = errors.New("role must be user, moderator or admin")
+	ErrLastAdmin             = errors.New("the last admin cannot be demoted")
 )
 
 const (
@@ -70,3 +107,18 @@ func ValidatePassword(password string) error {
 	}
 	return nil
 }
+
+// ValidateRole validates a user role
+func ValidateRole(role string) error {
+	if _, ok := roleRanks[role]; !ok {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// RoleAllows reports whether a user with role may use what requires the role
+// required. An empty or unknown role allows nothing.
+func RoleAllows(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
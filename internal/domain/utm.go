@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits on UTM parameters and presets
+const (
+	MaxUTMValueLength      = 100
+	MaxUTMPresetNameLength = 64
+)
+
+// UTM validation errors
+var (
+	ErrInvalidUTMValue      = fmt.Errorf("UTM values must not exceed %d characters or contain control characters", MaxUTMValueLength)
+	ErrEmptyUTM             = errors.New("at least one UTM parameter is required")
+	ErrInvalidUTMPresetName = fmt.Errorf("UTM preset name must be 1-%d characters and contain only alphanumeric characters, hyphens, and underscores", MaxUTMPresetNameLength)
+	ErrUTMPresetNotFound    = errors.New("UTM preset not found")
+)
+
+// UTMParams are the campaign parameters added to a destination URL. Empty
+// fields are left out.
+type UTMParams struct {
+	Source   string `json:"source"`
+	Medium   string `json:"medium"`
+	Campaign string `json:"campaign"`
+	Term     string `json:"term"`
+	Content  string `json:"content"`
+}
+
+// UTMPreset is a named set of UTM parameters saved by a user
+type UTMPreset struct {
+	Name string `json:"name"`
+	UTMParams
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Normalize puts UTM values into canonical form, so "Spring Sale " and
+// "spring_sale" end up in the same report: surrounding whitespace is trimmed,
+// inner whitespace becomes an underscore and letters are lower-cased.
+func (p *UTMParams) Normalize() error {
+	for _, value := range p.values() {
+		normalized, err := normalizeUTMValue(*value)
+		if err != nil {
+			return err
+		}
+		*value = normalized
+	}
+	return nil
+}
+
+// IsEmpty reports whether no UTM parameter is set
+func (p *UTMParams) IsEmpty() bool {
+	for _, value := range p.values() {
+		if *value != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Override returns p with the non-empty fields of other replacing its own
+func (p UTMParams) Override(other UTMParams) UTMParams {
+	overridden := p
+	dst := overridden.values()
+	for i, value := range other.values() {
+		if *value != "" {
+			*dst[i] = *value
+		}
+	}
+	return overridden
+}
+
+// values returns pointers to the fields in canonical parameter order
+func (p *UTMParams) values() [5]*string {
+	return [5]*string{&p.Source, &p.Medium, &p.Campaign, &p.Term, &p.Content}
+}
+
+// utmKeys are the query parameter names of UTMParams.values, in order
+var utmKeys = [5]string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+
+// ApplyUTM sets the non-empty UTM parameters on a destination URL, replacing
+// any values the URL already has for them. Other parameters keep their order
+// and encoding, the UTM parameters follow in canonical order, and the
+// fragment is preserved.
+func ApplyUTM(destination string, utm UTMParams) (string, error) {
+	target, err := url.Parse(destination)
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+
+	set := make(map[string]string, len(utmKeys))
+	for i, value := range utm.values() {
+		if *value != "" {
+			set[utmKeys[i]] = *value
+		}
+	}
+	if len(set) == 0 {
+		return destination, nil
+	}
+
+	var pairs []string
+	for _, pair := range strings.Split(target.RawQuery, "&") {
+		if _, replaced := set[queryKey(pair)]; pair == "" || replaced {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	for _, key := range utmKeys {
+		if value, ok := set[key]; ok {
+			pairs = append(pairs, key+"="+url.QueryEscape(value))
+		}
+	}
+	target.RawQuery = strings.Join(pairs, "&")
+
+	return target.String(), nil
+}
+
+// ValidateUTMPresetName validates the name of a UTM preset
+func ValidateUTMPresetName(name string) error {
+	if name == "" || len(name) > MaxUTMPresetNameLength {
+		return ErrInvalidUTMPresetName
+	}
+
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '-' || char == '_') {
+			return ErrInvalidUTMPresetName
+		}
+	}
+
+	return nil
+}
+
+func normalizeUTMValue(value string) (string, error) {
+	fields := strings.Fields(value)
+	normalized := strings.ToLower(strings.Join(fields, "_"))
+
+	if utf8.RuneCountInString(normalized) > MaxUTMValueLength {
+		return "", ErrInvalidUTMValue
+	}
+	for _, char := range normalized {
+		if unicode.IsControl(char) {
+			return "", ErrInvalidUTMValue
+		}
+	}
+
+	return normalized, nil
+}
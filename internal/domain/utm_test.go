@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyUTM(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		utm         UTMParams
+		want        string
+	}{
+		{
+			name:        "destination without a query",
+			destination: "https://example.com/page",
+			utm:         UTMParams{Source: "news", Medium: "email"},
+			want:        "https://example.com/page?utm_source=news&utm_medium=email",
+		},
+		{
+			name:        "canonical order whatever is set",
+			destination: "https://example.com/page",
+			utm:         UTMParams{Content: "banner", Source: "news", Term: "shoes", Campaign: "spring", Medium: "email"},
+			want:        "https://example.com/page?utm_source=news&utm_medium=email&utm_campaign=spring&utm_term=shoes&utm_content=banner",
+		},
+		{
+			name:        "other parameters keep their order and encoding",
+			destination: "https://example.com/search?q=a%2Bb&empty=&flag",
+			utm:         UTMParams{Source: "news"},
+			want:        "https://example.com/search?q=a%2Bb&empty=&flag&utm_source=news",
+		},
+		{
+			name:        "existing UTM value is overridden",
+			destination: "https://example.com/page?utm_source=old&a=1",
+			utm:         UTMParams{Source: "news"},
+			want:        "https://example.com/page?a=1&utm_source=news",
+		},
+		{
+			name:        "every existing value of a parameter is overridden",
+			destination: "https://example.com/page?utm_source=x&utm_source=y",
+			utm:         UTMParams{Source: "news"},
+			want:        "https://example.com/page?utm_source=news",
+		},
+		{
+			name:        "UTM parameters that are not set are kept",
+			destination: "https://example.com/page?utm_campaign=launch&utm_source=old",
+			utm:         UTMParams{Source: "news", Medium: "email"},
+			want:        "https://example.com/page?utm_campaign=launch&utm_source=news&utm_medium=email",
+		},
+		{
+			name:        "escaped existing key is overridden",
+			destination: "https://example.com/page?utm%5Fsource=old",
+			utm:         UTMParams{Source: "news"},
+			want:        "https://example.com/page?utm_source=news",
+		},
+		{
+			name:        "values are escaped",
+			destination: "https://example.com/page",
+			utm:         UTMParams{Campaign: "a&b c"},
+			want:        "https://example.com/page?utm_campaign=a%26b+c",
+		},
+		{
+			name:        "fragment is preserved",
+			destination: "https://example.com/app?a=1#/route",
+			utm:         UTMParams{Source: "news"},
+			want:        "https://example.com/app?a=1&utm_source=news#/route",
+		},
+		{
+			name:        "empty parameters leave the destination unchanged",
+			destination: "https://example.com/page?utm_source=old#top",
+			utm:         UTMParams{},
+			want:        "https://example.com/page?utm_source=old#top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyUTM(tt.destination, tt.utm)
+			if err != nil {
+				t.Fatalf("ApplyUTM(%q) error = %v", tt.destination, err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyUTM(%q, %+v)\n got  %s\n want %s", tt.destination, tt.utm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyUTMInvalidURL(t *testing.T) {
+	if _, err := ApplyUTM("http://[::1", UTMParams{Source: "news"}); err != ErrInvalidURL {
+		t.Errorf("ApplyUTM() error = %v, want ErrInvalidURL", err)
+	}
+}
+
+func TestUTMParamsOverride(t *testing.T) {
+	preset := UTMParams{Source: "news", Medium: "email", Campaign: "spring"}
+	got := preset.Override(UTMParams{Medium: "social", Content: "banner"})
+	want := UTMParams{Source: "news", Medium: "social", Campaign: "spring", Content: "banner"}
+	if got != want {
+		t.Errorf("Override() = %+v, want %+v", got, want)
+	}
+	if preset.Medium != "email" {
+		t.Error("Override() changed the preset")
+	}
+}
+
+func TestUTMParamsNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "lower-cased", value: "Newsletter", want: "newsletter"},
+		{name: "whitespace", value: "  Spring   Sale ", want: "spring_sale"},
+		{name: "control character", value: "a\x00b", wantErr: ErrInvalidUTMValue},
+		{name: "too long", value: strings.Repeat("a", MaxUTMValueLength+1), wantErr: ErrInvalidUTMValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := UTMParams{Campaign: tt.value}
+			err := p.Normalize()
+			if err != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && p.Campaign != tt.want {
+				t.Errorf("Normalize() = %q, want %q", p.Campaign, tt.want)
+			}
+		})
+	}
+}
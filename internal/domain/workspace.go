@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Workspace roles, from least to most privileged. Viewers see links and their
+// stats, editors also create, change and delete links, and owners also manage
+// members and the workspace itself.
+const (
+	WorkspaceRoleViewer = "viewer"
+	WorkspaceRoleEditor = "editor"
+	WorkspaceRoleOwner  = "owner"
+)
+
+// workspaceRoleRanks orders the workspace roles by privilege
+var workspaceRoleRanks = map[string]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+const MaxWorkspaceNameLength = 64
+
+// Workspace is a team that shares ownership of short URLs
+type Workspace struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"` // role of the requesting user
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkspaceMember is a user's membership of a workspace
+type WorkspaceMember struct {
+	UserID   int64     `json:"user_id"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// CreateWorkspaceRequest represents the request to create a workspace
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// AddWorkspaceMemberRequest represents the request to add a user to a
+// workspace
+type AddWorkspaceMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// UpdateWorkspaceMemberRequest represents the request to change the role of a
+// workspace member
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// Workspace errors
+var (
+	ErrInvalidWorkspaceName    = errors.New("workspace name must be 1-64 characters")
+	ErrInvalidWorkspaceRole    = errors.New("workspace role must be owner, editor or viewer")
+	ErrWorkspaceNotFound       = errors.New("workspace not found")
+	ErrWorkspaceForbidden      = errors.New("your workspace role does not allow this")
+	ErrWorkspaceMemberExists   = errors.New("user is already a member of the workspace")
+	ErrWorkspaceMemberNotFound = errors.New("user is not a member of the workspace")
+	ErrLastWorkspaceOwner      = errors.New("a workspace must keep at least one owner")
+	ErrWorkspaceInUse          = errors.New("workspace still has short URLs")
+)
+
+// ValidateWorkspaceRole validates a workspace role
+func ValidateWorkspaceRole(role string) error {
+	if _, ok := workspaceRoleRanks[role]; !ok {
+		return ErrInvalidWorkspaceRole
+	}
+	return nil
+}
+
+// WorkspaceRoleAllows reports whether a member with role may do what requires
+// the role required. An empty role, as for non-members, allows nothing.
+func WorkspaceRoleAllows(role, required string) bool {
+	rank, ok := workspaceRoleRanks[role]
+	return ok && rank >= workspaceRoleRanks[required]
+}
+
+// CanMoveOutOfWorkspace reports whether a member with role may take a URL out
+// of its workspace. Only the URL's creator and workspace owners can, so
+// editors cannot take shared links away from the team.
+func CanMoveOutOfWorkspace(url *URL, userID int64, role string) bool {
+	return url.UserID == userID || WorkspaceRoleAllows(role, WorkspaceRoleOwner)
+}
+
+// NormalizeWorkspaceName trims a workspace name and validates it
+func NormalizeWorkspaceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxWorkspaceNameLength {
+		return "", ErrInvalidWorkspaceName
+	}
+	for _, char := range name {
+		if char < ' ' || char == 0x7f {
+			return "", ErrInvalidWorkspaceName
+		}
+	}
+	return name, nil
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "testing"
+
+func TestCanMoveOutOfWorkspace(t *testing.T) {
+	const creatorID, memberID = 1, 2
+	url := &URL{UserID: creatorID, WorkspaceID: 10}
+
+	tests := []struct {
+		name   string
+		userID int64
+		role   string
+		want   bool
+	}{
+		{name: "creator as editor", userID: creatorID, role: WorkspaceRoleEditor, want: true},
+		{name: "creator as owner", userID: creatorID, role: WorkspaceRoleOwner, want: true},
+		{name: "owner", userID: memberID, role: WorkspaceRoleOwner, want: true},
+		{name: "editor is forbidden", userID: memberID, role: WorkspaceRoleEditor, want: false},
+		{name: "viewer is forbidden", userID: memberID, role: WorkspaceRoleViewer, want: false},
+		{name: "non-member is forbidden", userID: memberID, role: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanMoveOutOfWorkspace(url, tt.userID, tt.role); got != tt.want {
+				t.Errorf("CanMoveOutOfWorkspace(user %d, %q) = %v, want %v", tt.userID, tt.role, got, tt.want)
+			}
+		})
+	}
+}
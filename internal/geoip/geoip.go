@@ -0,0 +1,195 @@
+// Package geoip resolves IP addresses to a country, region and city using a
+// local MaxMind-format (.mmdb) database, such as GeoLite2-City or
+// GeoLite2-Country. The database file is watched and reloaded when it changes,
+// so it can be updated without restarting the service.
+package geoip
+
+import (
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DefaultReloadInterval is how often the database file is checked for changes
+const DefaultReloadInterval = time.Minute
+
+// Location is the result of an IP lookup. Fields are empty when unknown.
+type Location struct {
+	// Country is the ISO 3166-1 alpha-2 country code, e.g. "VN"
+	Country string
+	Region  string
+	City    string
+}
+
+// record mirrors the parts of a GeoIP2 City/Country record we use
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// Resolver looks up IP addresses in an .mmdb database. A Resolver without a
+// database path is disabled and resolves every address to an empty Location.
+type Resolver struct {
+	path string
+
+	mu      sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewResolver opens the database at path and starts watching it for changes.
+// A missing file is not an error: lookups return empty locations until the
+// file appears.
+func NewResolver(path string, reloadInterval time.Duration) *Resolver {
+	r := &Resolver{
+		path: path,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	if path == "" {
+		close(r.done)
+		return r
+	}
+
+	if reloadInterval <= 0 {
+		reloadInterval = DefaultReloadInterval
+	}
+
+	if err := r.reload(); err != nil {
+		log.Printf("GeoIP database not loaded, clicks will not be geolocated: %v", err)
+	}
+	go r.watch(reloadInterval)
+
+	return r
+}
+
+// Lookup resolves an IP address. Invalid, private or unknown addresses
+// resolve to an empty Location.
+func (r *Resolver) Lookup(ip string) Location {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.reader == nil {
+		return Location{}
+	}
+
+	var rec record
+	if err := r.reader.Lookup(parsed, &rec); err != nil {
+		return Location{}
+	}
+
+	loc := Location{
+		Country: rec.Country.ISOCode,
+		City:    rec.City.Names["en"],
+	}
+	if len(rec.Subdivisions) > 0 {
+		loc.Region = rec.Subdivisions[0].Names["en"]
+	}
+
+	return loc
+}
+
+// Close stops watching the database file and releases it
+func (r *Resolver) Close() error {
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reader == nil {
+		return nil
+	}
+	err := r.reader.Close()
+	r.reader = nil
+	return err
+}
+
+// watch polls the database file and reloads it whenever its modification time
+// changes
+func (r *Resolver) watch(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil {
+				continue
+			}
+
+			r.mu.RLock()
+			changed := !info.ModTime().Equal(r.modTime)
+			r.mu.RUnlock()
+
+			if changed {
+				if err := r.reload(); err != nil {
+					log.Printf("Failed to reload GeoIP database: %v", err)
+					continue
+				}
+				log.Printf("Reloaded GeoIP database %s", r.path)
+			}
+		}
+	}
+}
+
+// reload opens the database file and swaps it in for the current one
+func (r *Resolver) reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+
+	// Read the whole file rather than memory-mapping it, so the file can be
+	// replaced in place while it is in use
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	old := r.reader
+	r.reader = reader
+	r.modTime = info.ModTime()
+	r.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+
+	return nil
+}
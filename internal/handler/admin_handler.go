@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
+	"github.com/Faleeeee/URL_Shortener/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler handles operational and user management admin HTTP requests
+type AdminHandler struct {
+	clickWriter *service.ClickWriter
+	userService *service.UserService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(clickWriter *service.ClickWriter, userService *service.UserService) *AdminHandler {
+	return &AdminHandler{
+		clickWriter: clickWriter,
+		userService: userService,
+	}
+}
+
+// GetClickPipeline godoc
+// @Summary Get click pipeline status (Admin only)
+// @Description Get how many click events are waiting to be written, and how many were dropped since startup because the in-memory buffer was full or failed to be written to the database. Click counts are written with the events, so dropped and failed events are not counted either.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.APIResponse{data=domain.ClickPipelineStats} "Click pipeline status"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not an admin"
+// @Router /admin/clicks [get]
+func (h *AdminHandler) GetClickPipeline(c *gin.Context) {
+	stats := domain.ClickPipelineStats{
+		PendingEvents: h.clickWriter.Pending(),
+		DroppedEvents: h.clickWriter.Dropped(),
+		FailedEvents:  h.clickWriter.Failed(),
+	}
+
+	utils.SendSuccess(c, "Click pipeline status retrieved successfully", stats, nil)
+}
+
+// ListUsers godoc
+// @Summary List users (Admin only)
+// @Description Get a paginated list of all users and their roles
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of results to return" default(50)
+// @Param offset query int false "Number of results to skip" default(0)
+// @Success 200 {object} domain.APIResponse{data=[]domain.User} "List of users with pagination metadata"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not an admin"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	users, err := h.userService.ListUsers(limit, offset)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve users", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	meta := &domain.Meta{
+		Page:  offset/limit + 1,
+		Limit: limit,
+		Total: int64(len(users)), // Note: This is just the count of returned items, ideally we should have total count from DB
+	}
+
+	utils.SendSuccess(c, "Users retrieved successfully", users, meta)
+}
+
+// UpdateUserRole godoc
+// @Summary Change the role of a user (Admin only)
+// @Description Make a user a regular user, a moderator or an admin. The change applies from the user's next request. The last admin cannot be demoted.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body domain.UpdateUserRoleRequest true "New role"
+// @Success 200 {object} domain.APIResponse{data=domain.User} "Updated user"
+// @Failure 400 {object} domain.APIResponse "Invalid role"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not an admin"
+// @Failure 404 {object} domain.APIResponse "User not found"
+// @Failure 409 {object} domain.APIResponse "Last admin"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /admin/users/{id}/role [patch]
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	var req domain.UpdateUserRoleRequest
+
+	// Validate request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.UpdateRole(userID, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidRole):
+			utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+		case errors.Is(err, domain.ErrUserNotFound):
+			utils.SendError(c, http.StatusNotFound, "User not found", "USER_NOT_FOUND", "No user with this ID exists")
+		case errors.Is(err, domain.ErrLastAdmin):
+			utils.SendError(c, http.StatusConflict, err.Error(), "LAST_ADMIN", "Promote another admin first")
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "Failed to update role", "INTERNAL_ERROR", "An unexpected error occurred")
+		}
+		return
+	}
+
+	utils.SendSuccess(c, "User role updated successfully", user, nil)
+}
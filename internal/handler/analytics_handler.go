@@ -0,0 +1,387 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
+	"github.com/Faleeeee/URL_Shortener/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultTimeSeriesBuckets is how many buckets a time series covers when no
+// from parameter is given
+const defaultTimeSeriesBuckets = 30
+
+// AnalyticsHandler handles click analytics HTTP requests
+type AnalyticsHandler struct {
+	urlService       service.URLService
+	analyticsService service.AnalyticsService
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(urlService service.URLService, analyticsService service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		urlService:       urlService,
+		analyticsService: analyticsService,
+	}
+}
+
+// GetTimeSeries godoc
+// @Summary Get click time series
+// @Description Get click counts for a short URL bucketed by hour, day or week, with empty buckets zero-filled (owner or workspace members can view)
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param from query string false "Start of the range (RFC 3339 or YYYY-MM-DD), defaults to 30 intervals before to"
+// @Param to query string false "End of the range, exclusive (RFC 3339 or YYYY-MM-DD), defaults to now"
+// @Param interval query string false "Bucket size" Enums(hour, day, week) default(day)
+// @Param tz query string false "IANA time zone used for bucketing" default(UTC)
+// @Param include_bots query bool false "Include bot clicks" default(false)
+// @Success 200 {object} domain.APIResponse{data=domain.TimeSeriesResponse} "Click time series"
+// @Failure 400 {object} domain.APIResponse "Invalid query parameters"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias}/stats/timeseries [get]
+func (h *AnalyticsHandler) GetTimeSeries(c *gin.Context) {
+	q, err := parseTimeSeriesQuery(c)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	url, ok := getOwnedURL(c, h.urlService, domain.WorkspaceRoleViewer, "view")
+	if !ok {
+		return
+	}
+
+	response, err := h.analyticsService.GetTimeSeries(url, q)
+	if err != nil {
+		if isAnalyticsValidationError(err) {
+			utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve analytics", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	utils.SendSuccess(c, "Time series retrieved successfully", response, nil)
+}
+
+// GetUniqueVisitors godoc
+// @Summary Get daily unique visitors
+// @Description Get the approximate number of distinct visitors of a short URL per UTC day, with days without visitors zero-filled, and over the whole range (owner or workspace members can view). Visitors are told apart by a salted hash of IP address and User-Agent, and counted with HyperLogLog sketches, so counts are accurate to within a few percent. Bot clicks are not counted.
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param from query string false "Start of the range (RFC 3339 or YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "End of the range, exclusive (RFC 3339 or YYYY-MM-DD), defaults to now"
+// @Success 200 {object} domain.APIResponse{data=domain.UniqueVisitorsResponse} "Daily unique visitors"
+// @Failure 400 {object} domain.APIResponse "Invalid query parameters"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias}/stats/uniques [get]
+func (h *AnalyticsHandler) GetUniqueVisitors(c *gin.Context) {
+	var err error
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		if to, err = parseTimeParam(value, time.UTC); err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Invalid to parameter", "VALIDATION_ERROR", "to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+	}
+
+	from := to.Add(-defaultTimeSeriesBuckets * 24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		if from, err = parseTimeParam(value, time.UTC); err != nil {
+			utils.SendError(c, http.StatusBadRequest, "Invalid from parameter", "VALIDATION_ERROR", "from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+	}
+
+	url, ok := getOwnedURL(c, h.urlService, domain.WorkspaceRoleViewer, "view")
+	if !ok {
+		return
+	}
+
+	response, err := h.analyticsService.GetUniqueVisitors(url, from, to)
+	if err != nil {
+		if isAnalyticsValidationError(err) {
+			utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve analytics", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	utils.SendSuccess(c, "Unique visitors retrieved successfully", response, nil)
+}
+
+// GetReferrers godoc
+// @Summary Get referrer and campaign breakdown
+// @Description Get clicks on a short URL grouped by referring host or by the UTM parameters of the inbound request, most clicked first (owner or workspace members can view). Direct visits are reported as "(direct)", unparsable referrers as "(unknown)", referrals from the shortener itself as "(self)" and clicks without the UTM parameter as "(none)".
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param by query string false "Dimension to group by" Enums(host, utm_source, utm_medium, utm_campaign) default(host)
+// @Param limit query int false "Number of results to return" default(50)
+// @Param offset query int false "Number of results to skip" default(0)
+// @Param include_bots query bool false "Include bot clicks" default(false)
+// @Success 200 {object} domain.APIResponse{data=[]domain.BreakdownItem} "Click breakdown with pagination metadata"
+// @Failure 400 {object} domain.APIResponse "Invalid query parameters"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias}/stats/referrers [get]
+func (h *AnalyticsHandler) GetReferrers(c *gin.Context) {
+	by := c.DefaultQuery("by", domain.BreakdownReferrerHost)
+	if by != domain.BreakdownReferrerHost && by != domain.BreakdownUTMSource &&
+		by != domain.BreakdownUTMMedium && by != domain.BreakdownUTMCampaign {
+		utils.SendError(c, http.StatusBadRequest, domain.ErrInvalidBreakdown.Error(), "VALIDATION_ERROR", "by must be one of host, utm_source, utm_medium or utm_campaign")
+		return
+	}
+
+	h.sendBreakdown(c, by, "Referrer breakdown retrieved successfully")
+}
+
+// GetDevices godoc
+// @Summary Get device, OS and browser breakdown
+// @Description Get clicks on a short URL grouped by device class (mobile, tablet, desktop, bot), OS family or browser family, most clicked first (owner or workspace members can view)
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param by query string false "Dimension to group by" Enums(device, os, browser) default(device)
+// @Param limit query int false "Number of results to return" default(50)
+// @Param offset query int false "Number of results to skip" default(0)
+// @Param include_bots query bool false "Include bot clicks" default(false)
+// @Success 200 {object} domain.APIResponse{data=[]domain.BreakdownItem} "Click breakdown with pagination metadata"
+// @Failure 400 {object} domain.APIResponse "Invalid query parameters"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias}/stats/devices [get]
+func (h *AnalyticsHandler) GetDevices(c *gin.Context) {
+	by := c.DefaultQuery("by", domain.BreakdownDevice)
+	if by != domain.BreakdownDevice && by != domain.BreakdownOS && by != domain.BreakdownBrowser {
+		utils.SendError(c, http.StatusBadRequest, domain.ErrInvalidBreakdown.Error(), "VALIDATION_ERROR", "by must be one of device, os or browser")
+		return
+	}
+
+	h.sendBreakdown(c, by, "Device breakdown retrieved successfully")
+}
+
+// GetGeo godoc
+// @Summary Get geographic breakdown
+// @Description Get clicks on a short URL grouped by country (ISO 3166-1 alpha-2 code), region or city, most clicked first (owner or workspace members can view). Clicks that could not be geolocated are reported as "(none)".
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param by query string false "Dimension to group by" Enums(country, region, city) default(country)
+// @Param limit query int false "Number of results to return" default(50)
+// @Param offset query int false "Number of results to skip" default(0)
+// @Param include_bots query bool false "Include bot clicks" default(false)
+// @Success 200 {object} domain.APIResponse{data=[]domain.BreakdownItem} "Click breakdown with pagination metadata"
+// @Failure 400 {object} domain.APIResponse "Invalid query parameters"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias}/stats/geo [get]
+func (h *AnalyticsHandler) GetGeo(c *gin.Context) {
+	by := c.DefaultQuery("by", domain.BreakdownCountry)
+	if by != domain.BreakdownCountry && by != domain.BreakdownRegion && by != domain.BreakdownCity {
+		utils.SendError(c, http.StatusBadRequest, domain.ErrInvalidBreakdown.Error(), "VALIDATION_ERROR", "by must be one of country, region or city")
+		return
+	}
+
+	h.sendBreakdown(c, by, "Geographic breakdown retrieved successfully")
+}
+
+// GetRules godoc
+// @Summary Get targeting rule breakdown
+// @Description Get clicks on a short URL grouped by the targeting rule that picked their destination, such as "device:ios" or "geo:2", most clicked first (owner or workspace members can view). Clicks sent to the original URL are reported as "(none)".
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param limit query int false "Number of results to return" default(50)
+// @Param offset query int false "Number of results to skip" default(0)
+// @Param include_bots query bool false "Include bot clicks" default(false)
+// @Success 200 {object} domain.APIResponse{data=[]domain.BreakdownItem} "Click breakdown with pagination metadata"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias}/stats/rules [get]
+func (h *AnalyticsHandler) GetRules(c *gin.Context) {
+	h.sendBreakdown(c, domain.BreakdownRule, "Rule breakdown retrieved successfully")
+}
+
+// GetVariants godoc
+// @Summary Get clicks per variant
+// @Description Get the clicks sent to each variant of a short URL with weighted destinations, in configured order, followed by removed variants that still have clicks (owner or workspace members can view). Visitors matching a device or geo rule are not counted towards any variant.
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param include_bots query bool false "Include bot clicks" default(false)
+// @Success 200 {object} domain.APIResponse{data=domain.VariantStatsResponse} "Clicks per variant"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias}/stats/variants [get]
+func (h *AnalyticsHandler) GetVariants(c *gin.Context) {
+	url, ok := getOwnedURL(c, h.urlService, domain.WorkspaceRoleViewer, "view")
+	if !ok {
+		return
+	}
+
+	includeBots, _ := strconv.ParseBool(c.DefaultQuery("include_bots", "false"))
+
+	response, err := h.analyticsService.GetVariantStats(url, includeBots)
+	if err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve analytics", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	utils.SendSuccess(c, "Variant stats retrieved successfully", response, nil)
+}
+
+// sendBreakdown responds with a paginated click breakdown by dimension for
+// the URL in the alias path parameter
+func (h *AnalyticsHandler) sendBreakdown(c *gin.Context, dimension, message string) {
+	limit, offset := parsePagination(c)
+
+	url, ok := getOwnedURL(c, h.urlService, domain.WorkspaceRoleViewer, "view")
+	if !ok {
+		return
+	}
+
+	includeBots, _ := strconv.ParseBool(c.DefaultQuery("include_bots", "false"))
+
+	items, total, err := h.analyticsService.GetBreakdown(url, dimension, includeBots, limit, offset)
+	if err != nil {
+		if isAnalyticsValidationError(err) {
+			utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve analytics", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	meta := &domain.Meta{
+		Page:  offset/limit + 1,
+		Limit: limit,
+		Total: total,
+	}
+
+	utils.SendSuccess(c, message, items, meta)
+}
+
+// parsePagination reads the limit and offset query parameters, applying the
+// same default and bounds as the services so the response metadata is accurate
+func parsePagination(c *gin.Context) (int, int) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+// parseTimeSeriesQuery reads the from, to, interval and tz query parameters,
+// applying defaults for the ones that are missing
+func parseTimeSeriesQuery(c *gin.Context) (*domain.TimeSeriesQuery, error) {
+	q := &domain.TimeSeriesQuery{
+		Interval: c.DefaultQuery("interval", domain.IntervalDay),
+	}
+	q.IncludeBots, _ = strconv.ParseBool(c.DefaultQuery("include_bots", "false"))
+
+	step, err := domain.IntervalDuration(q.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	// LoadLocation also accepts "" and "Local", which Postgres does not know
+	tz := c.DefaultQuery("tz", "UTC")
+	if tz == "" || tz == "Local" {
+		return nil, domain.ErrInvalidTimezone
+	}
+	q.Location, err = time.LoadLocation(tz)
+	if err != nil {
+		return nil, domain.ErrInvalidTimezone
+	}
+
+	q.To = time.Now()
+	if to := c.Query("to"); to != "" {
+		if q.To, err = parseTimeParam(to, q.Location); err != nil {
+			return nil, errors.New("to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+	}
+
+	q.From = q.To.Add(-defaultTimeSeriesBuckets * step)
+	if from := c.Query("from"); from != "" {
+		if q.From, err = parseTimeParam(from, q.Location); err != nil {
+			return nil, errors.New("from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+	}
+
+	return q, nil
+}
+
+// parseTimeParam parses an RFC 3339 timestamp, or a plain date at midnight
+// in loc
+func parseTimeParam(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, loc)
+}
+
+// isAnalyticsValidationError reports whether err is caused by invalid
+// analytics query parameters
+func isAnalyticsValidationError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidInterval) ||
+		errors.Is(err, domain.ErrInvalidTimezone) ||
+		errors.Is(err, domain.ErrInvalidTimeRange) ||
+		errors.Is(err, domain.ErrTimeRangeTooLarge) ||
+		errors.Is(err, domain.ErrInvalidBreakdown)
+}
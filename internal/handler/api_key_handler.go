@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
+	"github.com/Faleeeee/URL_Shortener/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles API key HTTP requests
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create a long-lived API key for programmatic access, limited to the given scopes: links:write (create, update and delete short URLs), links:read (read short URLs) and stats:read (read click statistics). The key is only shown in this response. Send it in the X-API-Key header, or as "Authorization: ApiKey <key>".
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.CreateAPIKeyRequest true "Key name and scopes"
+// @Success 200 {object} domain.APIResponse{data=domain.CreateAPIKeyResponse} "API key created"
+// @Failure 400 {object} domain.APIResponse "Invalid name or scopes"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /user/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req domain.CreateAPIKeyRequest
+
+	// Validate request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	key, rawKey, err := h.apiKeyService.Create(userID.(int64), &req)
+	if err != nil {
+		h.sendAPIKeyError(c, err)
+		return
+	}
+
+	response := domain.CreateAPIKeyResponse{
+		APIKey: *key,
+		Key:    rawKey,
+	}
+
+	utils.SendSuccess(c, "API key created successfully. Store it now, it will not be shown again", response, nil)
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description Get the API keys of the authenticated user, including revoked ones, newest first. Keys are identified by their first characters; the full keys cannot be retrieved.
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.APIResponse{data=[]domain.APIKey} "API keys"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /user/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	keys, err := h.apiKeyService.List(userID.(int64))
+	if err != nil {
+		h.sendAPIKeyError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "API keys retrieved successfully", keys, nil)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Permanently disable an API key of the authenticated user
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} domain.APIResponse "API key revoked"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 404 {object} domain.APIResponse "API key not found or already revoked"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /user/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(userID.(int64), id); err != nil {
+		h.sendAPIKeyError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "API key revoked successfully", nil, nil)
+}
+
+// sendAPIKeyError maps an API key error to an error response
+func (h *APIKeyHandler) sendAPIKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidAPIKeyName),
+		errors.Is(err, domain.ErrInvalidScope):
+		utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		utils.SendError(c, http.StatusNotFound, "API key not found", "API_KEY_NOT_FOUND", "No active API key with this ID exists")
+	default:
+		utils.SendError(c, http.StatusInternalServerError, "Failed to process API key", "INTERNAL_ERROR", "An unexpected error occurred")
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
+	"github.com/Faleeeee/URL_Shortener/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DomainHandler handles custom domain HTTP requests
+type DomainHandler struct {
+	domainService *service.DomainService
+}
+
+// NewDomainHandler creates a new custom domain handler
+func NewDomainHandler(domainService *service.DomainService) *DomainHandler {
+	return &DomainHandler{
+		domainService: domainService,
+	}
+}
+
+// RegisterDomain godoc
+// @Summary Add a custom domain
+// @Description Register a branded domain, such as go.example.com, to serve short URLs from. The domain must be verified by creating the returned DNS TXT record and calling the verify endpoint, and pointed at the shortener, before short URLs can use it.
+// @Tags Domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.RegisterDomainRequest true "Domain to add"
+// @Success 200 {object} domain.APIResponse{data=domain.DomainResponse} "Domain added, with its verification record"
+// @Failure 400 {object} domain.APIResponse "Invalid hostname"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 409 {object} domain.APIResponse "Domain already registered"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /domains [post]
+func (h *DomainHandler) RegisterDomain(c *gin.Context) {
+	var req domain.RegisterDomainRequest
+
+	// Validate request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	d, err := h.domainService.Register(userID.(int64), req.Hostname)
+	if err != nil {
+		h.sendDomainError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Domain added successfully", newDomainResponse(d), nil)
+}
+
+// ListDomains godoc
+// @Summary List custom domains
+// @Description Get the custom domains of the authenticated user and their verification state
+// @Tags Domains
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.APIResponse{data=[]domain.DomainResponse} "Custom domains"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /domains [get]
+func (h *DomainHandler) ListDomains(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	domains, err := h.domainService.List(userID.(int64))
+	if err != nil {
+		h.sendDomainError(c, err)
+		return
+	}
+
+	response := make([]domain.DomainResponse, 0, len(domains))
+	for _, d := range domains {
+		response = append(response, newDomainResponse(d))
+	}
+
+	utils.SendSuccess(c, "Domains retrieved successfully", response, nil)
+}
+
+// VerifyDomain godoc
+// @Summary Verify a custom domain
+// @Description Look up the verification DNS TXT record of a custom domain and mark the domain verified if it holds the expected value. DNS changes can take a while to propagate; the call can be retried.
+// @Tags Domains
+// @Produce json
+// @Security BearerAuth
+// @Param hostname path string true "Domain hostname"
+// @Success 200 {object} domain.APIResponse{data=domain.DomainResponse} "Domain verified"
+// @Failure 400 {object} domain.APIResponse "Verification record not found"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 404 {object} domain.APIResponse "Domain not found"
+// @Failure 409 {object} domain.APIResponse "Domain already verified by another user"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /domains/{hostname}/verify [post]
+func (h *DomainHandler) VerifyDomain(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	d, err := h.domainService.Verify(userID.(int64), c.Param("hostname"))
+	if err != nil {
+		h.sendDomainError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Domain verified successfully", newDomainResponse(d), nil)
+}
+
+// DeleteDomain godoc
+// @Summary Remove a custom domain
+// @Description Remove a custom domain of the authenticated user. Domains that still have short URLs, including deleted ones, cannot be removed.
+// @Tags Domains
+// @Produce json
+// @Security BearerAuth
+// @Param hostname path string true "Domain hostname"
+// @Success 200 {object} domain.APIResponse "Domain removed"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 404 {object} domain.APIResponse "Domain not found"
+// @Failure 409 {object} domain.APIResponse "Domain still has short URLs"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /domains/{hostname} [delete]
+func (h *DomainHandler) DeleteDomain(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	if err := h.domainService.Delete(userID.(int64), c.Param("hostname")); err != nil {
+		h.sendDomainError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Domain removed successfully", nil, nil)
+}
+
+// sendDomainError maps a custom domain error to an error response
+func (h *DomainHandler) sendDomainError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidHostname),
+		errors.Is(err, domain.ErrReservedHostname),
+		errors.Is(err, domain.ErrVerificationFailed):
+		utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, domain.ErrDomainNotFound):
+		utils.SendError(c, http.StatusNotFound, "Domain not found", "DOMAIN_NOT_FOUND", "No custom domain with this hostname exists")
+	case errors.Is(err, domain.ErrDomainExists),
+		errors.Is(err, domain.ErrDomainTaken),
+		errors.Is(err, domain.ErrDomainInUse):
+		utils.SendError(c, http.StatusConflict, err.Error(), "DOMAIN_CONFLICT", err.Error())
+	default:
+		utils.SendError(c, http.StatusInternalServerError, "Failed to process domain", "INTERNAL_ERROR", "An unexpected error occurred")
+	}
+}
+
+// newDomainResponse builds the response for a custom domain
+func newDomainResponse(d *domain.CustomDomain) domain.DomainResponse {
+	return domain.DomainResponse{
+		Hostname:           d.Hostname,
+		Verified:           d.IsVerified(),
+		VerifiedAt:         d.VerifiedAt,
+		VerificationRecord: d.VerificationRecord(),
+		CreatedAt:          d.CreatedAt,
+	}
+}
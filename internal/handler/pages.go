@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/Faleeeee/URL_Shortener/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// pages holds the HTML pages served to visitors of short links, keyed by file
+// name without extension. Every page is rendered inside templates/layout.html.
+var pages = loadPages()
+
+func loadPages() map[string]*template.Template {
+	paths, err := fs.Glob(templateFS, "templates/*.html")
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := make(map[string]*template.Template)
+	for _, p := range paths {
+		name := strings.TrimSuffix(path.Base(p), ".html")
+		if name == "layout" {
+			continue
+		}
+		loaded[name] = template.Must(template.ParseFS(templateFS, "templates/layout.html", p))
+	}
+
+	return loaded
+}
+
+// renderPage renders a branded HTML page with the given status code
+func renderPage(c *gin.Context, status int, name string, data gin.H) {
+	var buf bytes.Buffer
+	if err := pages[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		utils.SendError(c, http.StatusInternalServerError, "Failed to render page", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
@@ -3,8 +3,10 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Faleeeee/URL_Shortener/internal/domain"
 	"github.com/Faleeeee/URL_Shortener/internal/repository"
@@ -14,15 +16,36 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// permanentRedirectMaxAge bounds how long browsers may cache a permanent
+// redirect, so changing the destination eventually reaches everyone
+const permanentRedirectMaxAge = 24 * time.Hour
+
+// unlockCookiePrefix is prepended to the URL ID to name the cookie that
+// remembers a password-protected link was unlocked. Aliases are only unique
+// per domain, so they cannot name it.
+const unlockCookiePrefix = "unlock_"
+
+// variantCookiePrefix is prepended to the alias to name the cookie that
+// remembers which variant a visitor was sent to, for variantCookieMaxAge
+const variantCookiePrefix = "variant_"
+
+const variantCookieMaxAge = 30 * 24 * time.Hour
+
+// previewSuffix appended to an alias asks for the preview page instead of the
+// redirect, as in /abc123+
+const previewSuffix = "+"
+
 type URLHandler struct {
 	service service.URLService
+	guard   *service.LinkGuard
 	baseURL string
 }
 
 // NewURLHandler creates a new URL handler
-func NewURLHandler(service service.URLService, baseURL string) *URLHandler {
+func NewURLHandler(service service.URLService, guard *service.LinkGuard, baseURL string) *URLHandler {
 	return &URLHandler{
 		service: service,
+		guard:   guard,
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 	}
 }
@@ -34,10 +57,12 @@ func NewURLHandler(service service.URLService, baseURL string) *URLHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param request body domain.ShortenRequest true "URL to shorten and optional alias"
 // @Success 200 {object} domain.APIResponse{data=domain.ShortenResponse} "Successfully created short URL"
 // @Failure 400 {object} domain.APIResponse "Invalid request or validation error"
 // @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - viewer of the workspace"
 // @Failure 409 {object} domain.APIResponse "Alias already exists"
 // @Failure 500 {object} domain.APIResponse "Internal server error"
 // @Router /url/shorten [post]
@@ -56,16 +81,16 @@ func (h *URLHandler) ShortenURL(c *gin.Context) {
 		return
 	}
 
-	url, err := h.service.ShortenURL(req.URL, req.Alias, userID.(int64))
+	url, err := h.service.ShortenURL(&req, userID.(int64))
 	if err != nil {
-		if errors.Is(err, domain.ErrInvalidURL) ||
-			errors.Is(err, domain.ErrURLTooLong) ||
-			errors.Is(err, domain.ErrInvalidAlias) ||
-			errors.Is(err, domain.ErrAliasTooLong) ||
-			errors.Is(err, domain.ErrPrivateURL) {
+		if isValidationError(err) {
 			utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
 			return
 		}
+		if errors.Is(err, domain.ErrWorkspaceForbidden) {
+			utils.SendError(c, http.StatusForbidden, "You don't have permission to add URLs to this workspace", "FORBIDDEN", "Viewers cannot create URLs in a workspace")
+			return
+		}
 
 		// Duplicate alias error
 		if errors.Is(err, repository.ErrDuplicateAlias) ||
@@ -82,7 +107,7 @@ func (h *URLHandler) ShortenURL(c *gin.Context) {
 	// Build response
 	response := domain.ShortenResponse{
 		Alias:       url.Alias,
-		ShortURL:    h.baseURL + "/" + url.Alias,
+		ShortURL:    h.shortURL(url),
 		OriginalURL: url.OriginalURL,
 	}
 
@@ -91,105 +116,518 @@ func (h *URLHandler) ShortenURL(c *gin.Context) {
 
 // RedirectURL godoc
 // @Summary Redirect to original URL
-// @Description Redirect to the original URL using the short alias
+// @Description Redirect to the original URL using the short alias. Crawlers, link previewers, HEAD requests and prefetches are counted as bot clicks. Appending "+" to the alias, or passing preview=1, shows a preview page with the destination instead of redirecting; links with force_preview always show it until the visitor confirms. Other query parameters are passed on to the destination as set by the link's query_policy. Crawlers that unfurl shared links, such as Slackbot or Twitterbot, get a page with the link's og_title, og_description and og_image instead of the redirect when any is set.
 // @Tags URL Shortener
-// @Param alias path string true "Short URL alias"
-// @Success 302 "Redirects to original URL"
+// @Param alias path string true "Short URL alias, optionally followed by + for a preview"
+// @Param preview query bool false "Show the preview page instead of redirecting"
+// @Param confirm query bool false "Skip the forced preview page after the visitor confirmed"
+// @Success 200 "Password prompt for protected links, preview page, or social preview page for crawlers unfurling links with og_* overrides (HTML page)"
+// @Success 301 "Redirects to original URL (permanent, cacheable)"
+// @Success 302 "Redirects to original URL (temporary, default)"
+// @Success 307 "Redirects to original URL (temporary, method preserved)"
+// @Success 308 "Redirects to original URL (permanent, method preserved, cacheable)"
 // @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 410 "Short URL has been deleted or has expired without a fallback URL (HTML page)"
 // @Failure 500 {object} domain.APIResponse "Internal server error"
 // @Router /{alias} [get]
 func (h *URLHandler) RedirectURL(c *gin.Context) {
-	alias := c.Param("alias")
+	alias, preview := strings.CutSuffix(c.Param("alias"), previewSuffix)
+	preview = preview || c.Query("preview") == "1"
 
-	// Get URL by alias
-	url, err := h.service.GetURLByAlias(alias)
+	url, ok := h.resolveURL(c, alias)
+	if !ok {
+		return
+	}
+
+	// Ask for the password of protected links, unless already unlocked. This
+	// comes before the preview, which would reveal the destination.
+	if url.PasswordHash != "" && !h.isUnlocked(c, url) {
+		renderPasswordPrompt(c, http.StatusOK, url, "")
+		return
+	}
+
+	// Show the preview page when asked for, or when the link forces it and the
+	// visitor has not confirmed yet. Previews are not counted as clicks.
+	if preview || (url.ForcePreview && c.Query("confirm") != "1") {
+		h.renderPreview(c, url)
+		return
+	}
+
+	// Count the click, enforcing expiry time and click cap, and pick the
+	// destination for this visitor
+	visit := newVisit(c, url)
+	destination, err := h.service.FollowURL(url, visit)
+	if err != nil {
+		if errors.Is(err, service.ErrURLExpired) {
+			h.redirectExpired(c, url)
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	// Crawlers unfurling a shared link get the link's social preview instead
+	// of the destination's. The click was recorded as a bot click.
+	if visit.Unfurler && url.HasOpenGraph() {
+		h.renderUnfurl(c, url, destination)
+		return
+	}
+
+	// Redirect with the link's status code, telling browsers whether they may
+	// cache it
+	h.rememberVariant(c, url, visit)
+	c.Header("Cache-Control", redirectCacheControl(url, time.Now()))
+	c.Redirect(url.RedirectType, destination)
+}
+
+// redirectCacheControl returns the Cache-Control header for a redirect.
+// Permanent redirects may be cached for a day, or until the link expires, by
+// shared caches too unless the destination depends on the visitor.
+// Temporary redirects, links with a click cap and password-protected links
+// must not be cached, so every click reaches the shortener and is checked.
+func redirectCacheControl(url *domain.URL, now time.Time) string {
+	if !domain.IsPermanentRedirect(url.RedirectType) || url.MaxClicks != nil || url.PasswordHash != "" {
+		return "no-cache, no-store, must-revalidate"
+	}
+
+	maxAge := permanentRedirectMaxAge
+	if url.ExpiresAt != nil && url.ExpiresAt.Sub(now) < maxAge {
+		maxAge = url.ExpiresAt.Sub(now)
+	}
+
+	scope := "public"
+	if len(url.GeoRules) > 0 || len(url.DeviceRules) > 0 || len(url.Variants) > 0 || url.HasOpenGraph() {
+		scope = "private"
+	}
+
+	return scope + ", max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+}
+
+// UnlockURL godoc
+// @Summary Unlock a password-protected short URL
+// @Description Check the password of a protected short URL submitted from the password prompt. On success, the unlock is remembered in a signed, short-lived cookie and the visitor is redirected to the original URL. Wrong passwords are rate limited per alias.
+// @Tags URL Shortener
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param alias path string true "Short URL alias"
+// @Param password formData string true "Link password"
+// @Success 303 "Redirects to original URL"
+// @Failure 403 "Incorrect password (HTML page)"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 410 "Short URL has been deleted or has expired without a fallback URL (HTML page)"
+// @Failure 429 "Too many incorrect passwords (HTML page)"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /{alias} [post]
+func (h *URLHandler) UnlockURL(c *gin.Context) {
+	url, ok := h.resolveURL(c, c.Param("alias"))
+	if !ok {
+		return
+	}
+
+	// Links without a password need no unlocking
+	if url.PasswordHash == "" {
+		c.Redirect(http.StatusSeeOther, "/"+url.Alias)
+		return
+	}
+
+	token, expires, err := h.guard.Unlock(url, c.PostForm("password"), time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTooManyAttempts):
+			renderPasswordPrompt(c, http.StatusTooManyRequests, url, "Too many incorrect passwords. Please try again later.")
+		case errors.Is(err, service.ErrIncorrectPassword):
+			renderPasswordPrompt(c, http.StatusForbidden, url, "Incorrect password. Please try again.")
+		default:
+			utils.SendError(c, http.StatusInternalServerError, "Failed to unlock URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		}
+		return
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     unlockCookieName(url),
+		Value:    token,
+		Path:     "/" + url.Alias,
+		Expires:  expires,
+		MaxAge:   int(time.Until(expires).Seconds()),
+		Secure:   strings.HasPrefix(h.baseURL, "https://"),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	visit := newVisit(c, url)
+	destination, err := h.service.FollowURL(url, visit)
+	if err != nil {
+		if errors.Is(err, service.ErrURLExpired) {
+			h.redirectExpired(c, url)
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	// 303 turns the form POST into a GET of the destination, whatever the
+	// link's own redirect type
+	h.rememberVariant(c, url, visit)
+	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.Redirect(http.StatusSeeOther, destination)
+}
+
+// rememberVariant stores the variant a visitor was sent to in a cookie, so
+// they keep seeing it even if their IP address changes
+func (h *URLHandler) rememberVariant(c *gin.Context, url *domain.URL, visit *domain.Visit) {
+	if visit.Variant == "" || visit.Variant == visit.AssignedVariant {
+		return
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     variantCookiePrefix + url.Alias,
+		Value:    visit.Variant,
+		Path:     "/" + url.Alias,
+		MaxAge:   int(variantCookieMaxAge.Seconds()),
+		Secure:   strings.HasPrefix(h.baseURL, "https://"),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// resolveURL looks up the URL for an alias requested by a visitor,
+// responding with a gone page or an error if it cannot be followed
+func (h *URLHandler) resolveURL(c *gin.Context, alias string) (*domain.URL, bool) {
+	url, err := h.service.ResolveURL(c.Request.Host, alias)
 	if err != nil {
+		if errors.Is(err, service.ErrURLGone) {
+			renderPage(c, http.StatusGone, "gone", gin.H{"Title": "This link is no longer available", "Alias": alias})
+			return nil, false
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			utils.SendError(c, http.StatusNotFound, "Short URL not found", "URL_NOT_FOUND", "The requested alias does not exist")
-			return
+			return nil, false
 		}
 		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		return nil, false
+	}
+
+	return url, true
+}
+
+// queryParam is a query parameter carried through a form as a hidden input
+type queryParam struct {
+	Name  string
+	Value string
+}
+
+// renderPreview shows where a URL leads without counting a click. Expired
+// URLs are handled as on redirect, since their destination no longer applies.
+// The request's query parameters are carried through the continue form, so
+// the query policy and UTM capture see them when the visitor confirms.
+func (h *URLHandler) renderPreview(c *gin.Context, url *domain.URL) {
+	if url.IsExpired(time.Now()) {
+		h.redirectExpired(c, url)
 		return
 	}
 
-	// Increment click counter (fire and forget - don't block redirect)
-	go h.service.IncrementClickCount(alias)
+	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	renderPage(c, http.StatusOK, "preview", gin.H{
+		"Title":       "Check where this link goes",
+		"Alias":       url.Alias,
+		"Destination": url.OriginalURL,
+		"PageTitle":   url.Title,
+		"CreatedAt":   url.CreatedAt,
+		"Query":       forwardedQuery(c),
+	})
+}
+
+// forwardedQuery returns the query parameters of a request meant for the
+// destination, sorted by name
+func forwardedQuery(c *gin.Context) []queryParam {
+	query := c.Request.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		if !domain.IsReservedQueryParam(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
 
-	// Redirect to original URL (302 Found - temporary redirect)
-	c.Redirect(http.StatusFound, url.OriginalURL)
+	var params []queryParam
+	for _, name := range names {
+		for _, value := range query[name] {
+			params = append(params, queryParam{Name: name, Value: value})
+		}
+	}
+	return params
+}
+
+// renderUnfurl serves the Open Graph and Twitter Card overrides of a URL to a
+// crawler building a preview card, with a link to the destination. The page
+// title falls back to the destination page title, then to the destination.
+func (h *URLHandler) renderUnfurl(c *gin.Context, url *domain.URL, destination string) {
+	title := url.OGTitle
+	if title == "" {
+		title = url.Title
+	}
+	if title == "" {
+		title = destination
+	}
+
+	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	renderPage(c, http.StatusOK, "unfurl", gin.H{
+		"Title":       title,
+		"Description": url.OGDescription,
+		"Image":       url.OGImage,
+		"ShortURL":    h.shortURL(url),
+		"Destination": destination,
+	})
+}
+
+// unlockCookieName returns the name of the unlock cookie of a URL
+func unlockCookieName(url *domain.URL) string {
+	return unlockCookiePrefix + strconv.FormatInt(url.ID, 10)
+}
+
+// isUnlocked reports whether the request carries a valid unlock cookie for a
+// password-protected URL
+func (h *URLHandler) isUnlocked(c *gin.Context, url *domain.URL) bool {
+	token, err := c.Cookie(unlockCookieName(url))
+	if err != nil {
+		return false
+	}
+	return h.guard.IsUnlocked(url, token, time.Now())
+}
+
+// renderPasswordPrompt shows the password form of a protected URL. The prompt
+// must never be cached, or a later visit could skip it.
+func renderPasswordPrompt(c *gin.Context, status int, url *domain.URL, message string) {
+	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	renderPage(c, status, "password", gin.H{"Title": "This link is password protected", "Alias": url.Alias, "Error": message})
+}
+
+// newVisit captures the request details that are recorded with a click on a URL
+func newVisit(c *gin.Context, url *domain.URL) *domain.Visit {
+	assignedVariant, _ := c.Cookie(variantCookiePrefix + url.Alias)
+
+	return &domain.Visit{
+		Time:           time.Now(),
+		Method:         c.Request.Method,
+		IP:             c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Referrer:       c.Request.Referer(),
+		AcceptLanguage: c.GetHeader("Accept-Language"),
+		Purpose:        purposeHeader(c),
+		Query:          c.Request.URL.Query(),
+		PlatformHint:   c.GetHeader("Sec-CH-UA-Platform"),
+		MobileHint:     c.GetHeader("Sec-CH-UA-Mobile"),
+
+		AssignedVariant: assignedVariant,
+	}
+}
+
+// purposeHeader returns the first prefetch or preview hint sent with the request
+func purposeHeader(c *gin.Context) string {
+	for _, header := range []string{"Sec-Purpose", "Purpose", "X-Purpose", "X-Moz"} {
+		if value := c.GetHeader(header); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// redirectExpired sends visitors of an expired URL to its fallback URL, or
+// shows an expiry page if none is set
+func (h *URLHandler) redirectExpired(c *gin.Context, url *domain.URL) {
+	if url.ExpiredRedirectURL != "" {
+		c.Redirect(http.StatusFound, url.ExpiredRedirectURL)
+		return
+	}
+
+	renderPage(c, http.StatusGone, "expired", gin.H{"Title": "This link has expired", "Alias": url.Alias})
 }
 
 // GetURLInfo godoc
 // @Summary Get URL information
-// @Description Get detailed information about a shortened URL including click count (only owner can view)
+// @Description Get detailed information about a shortened URL including click count (owner or workspace members can view)
 // @Tags URL Shortener
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
 // @Success 200 {object} domain.APIResponse{data=domain.URLInfoResponse} "URL information"
 // @Failure 401 {object} domain.APIResponse "Unauthorized"
-// @Failure 403 {object} domain.APIResponse "Forbidden - not owner"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
 // @Failure 404 {object} domain.APIResponse "Short URL not found"
 // @Failure 500 {object} domain.APIResponse "Internal server error"
 // @Router /url/links/{alias} [get]
 func (h *URLHandler) GetURLInfo(c *gin.Context) {
-	alias := c.Param("alias")
+	url, ok := getOwnedURL(c, h.service, domain.WorkspaceRoleViewer, "view")
+	if !ok {
+		return
+	}
 
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+	utils.SendSuccess(c, "URL information retrieved successfully", h.newURLInfoResponse(url), nil)
+}
+
+// UpdateURL godoc
+// @Summary Update a shortened URL
+// @Description Change the destination and other mutable settings of a short URL without changing its alias (owner or workspace editors and owners can update). Setting workspace_id moves the URL into a workspace the user is an editor or owner of; 0 moves it back to the personal URLs of its creator. Only the creator and owners of the current workspace can move a URL out of it.
+// @Tags URL Shortener
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param request body domain.UpdateURLRequest true "Fields to update"
+// @Success 200 {object} domain.APIResponse{data=domain.URLInfoResponse} "Updated URL information"
+// @Failure 400 {object} domain.APIResponse "Invalid request or validation error"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias} [patch]
+func (h *URLHandler) UpdateURL(c *gin.Context) {
+	var req domain.UpdateURLRequest
+
+	// Validate request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
 		return
 	}
 
-	// Get URL by alias
-	url, err := h.service.GetURLByAlias(alias)
+	url, ok := getOwnedURL(c, h.service, domain.WorkspaceRoleEditor, "update")
+	if !ok {
+		return
+	}
+
+	url, err := h.service.UpdateURL(url, &req, c.GetInt64("user_id"))
 	if err != nil {
+		if isValidationError(err) {
+			utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+			return
+		}
+		if errors.Is(err, domain.ErrWorkspaceForbidden) {
+			utils.SendError(c, http.StatusForbidden, "You don't have permission to move this URL", "FORBIDDEN", "Only the creator and workspace owners can move a URL out of its workspace, and only into a workspace you can add URLs to")
+			return
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			utils.SendError(c, http.StatusNotFound, "Short URL not found", "URL_NOT_FOUND", "The requested alias does not exist")
 			return
 		}
-		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		utils.SendError(c, http.StatusInternalServerError, "Failed to update URL", "INTERNAL_ERROR", "An unexpected error occurred")
 		return
 	}
 
-	// Check if user is the owner of this URL
-	if url.UserID != userID.(int64) {
-		utils.SendError(c, http.StatusForbidden, "You don't have permission to view this URL", "FORBIDDEN", "You are not the owner of this URL")
+	utils.SendSuccess(c, "URL updated successfully", h.newURLInfoResponse(url), nil)
+}
+
+// DeleteURL godoc
+// @Summary Delete a shortened URL
+// @Description Soft-delete a short URL. The alias stays reserved and redirects to it return 410 Gone (owner or workspace editors and owners can delete). With purge=true the URL, soft-deleted or not, and its click events are removed permanently and the alias is freed; only the creator and workspace owners can purge. Custom domains can only be removed once they have no URLs left, deleted ones included.
+// @Tags URL Shortener
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Param purge query bool false "Remove the URL permanently" default(false)
+// @Success 200 {object} domain.APIResponse "URL deleted"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not the owner or a workspace member with a sufficient role"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /url/links/{alias} [delete]
+func (h *URLHandler) DeleteURL(c *gin.Context) {
+	if purge, _ := strconv.ParseBool(c.DefaultQuery("purge", "false")); purge {
+		h.purgeOwnURL(c)
 		return
 	}
 
-	// Build response
-	response := domain.URLInfoResponse{
-		Alias:       url.Alias,
-		OriginalURL: url.OriginalURL,
-		UserID:      url.UserID,
-		ClickCount:  url.ClickCount,
-		CreatedAt:   url.CreatedAt,
-		UpdatedAt:   url.UpdatedAt,
+	url, ok := getOwnedURL(c, h.service, domain.WorkspaceRoleEditor, "delete")
+	if !ok {
+		return
 	}
 
-	utils.SendSuccess(c, "URL information retrieved successfully", response, nil)
+	if err := h.service.DeleteURL(url); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.SendError(c, http.StatusNotFound, "Short URL not found", "URL_NOT_FOUND", "The requested alias does not exist")
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to delete URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	utils.SendSuccess(c, "URL deleted successfully", nil, nil)
+}
+
+// purgeOwnURL permanently removes a URL of the user, soft-deleted or not
+func (h *URLHandler) purgeOwnURL(c *gin.Context) {
+	if err := h.service.PurgeOwnURL(c.Query("domain"), c.Param("alias"), c.GetInt64("user_id")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.SendError(c, http.StatusNotFound, "Short URL not found", "URL_NOT_FOUND", "The requested alias does not exist")
+			return
+		}
+		if errors.Is(err, domain.ErrWorkspaceForbidden) {
+			utils.SendError(c, http.StatusForbidden, "You don't have permission to purge this URL", "FORBIDDEN", "Only the creator and workspace owners can purge a URL")
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to purge URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	utils.SendSuccess(c, "URL purged successfully", nil, nil)
+}
+
+// PurgeURL godoc
+// @Summary Permanently delete a shortened URL (Admin only)
+// @Description Remove a short URL from the database, including soft-deleted ones, and free its alias
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param alias path string true "Short URL alias"
+// @Param domain query string false "Custom domain of the short URL, if not on BASE_URL"
+// @Success 200 {object} domain.APIResponse "URL purged"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not an admin"
+// @Failure 404 {object} domain.APIResponse "Short URL not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /admin/url/{alias} [delete]
+func (h *URLHandler) PurgeURL(c *gin.Context) {
+	alias := c.Param("alias")
+
+	if err := h.service.PurgeURL(c.Query("domain"), alias); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.SendError(c, http.StatusNotFound, "Short URL not found", "URL_NOT_FOUND", "The requested alias does not exist")
+			return
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to purge URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		return
+	}
+
+	utils.SendSuccess(c, "URL purged successfully", nil, nil)
 }
 
 // ListURLs godoc
-// @Summary List all shortened URLs (Admin only)
+// @Summary List all shortened URLs (Moderator or admin)
 // @Description Get a paginated list of all shortened URLs in the system
 // @Tags Admin
 // @Produce json
 // @Security BearerAuth
 // @Param limit query int false "Number of results to return" default(50)
 // @Param offset query int false "Number of results to skip" default(0)
+// @Param include_deleted query bool false "Include soft-deleted URLs" default(false)
 // @Success 200 {object} domain.APIResponse{data=[]domain.URL} "List of URLs with pagination metadata"
 // @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not a moderator or admin"
 // @Failure 500 {object} domain.APIResponse "Internal server error"
 // @Router /admin/url [get]
 func (h *URLHandler) ListURLs(c *gin.Context) {
 	// Parse pagination parameters
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	includeDeleted, _ := strconv.ParseBool(c.DefaultQuery("include_deleted", "false"))
 
 	// Get URLs
-	urls, err := h.service.ListURLs(limit, offset)
+	urls, err := h.service.ListURLs(limit, offset, includeDeleted)
 	if err != nil {
 		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve URLs", "INTERNAL_ERROR", "An unexpected error occurred")
 		return
@@ -212,14 +650,17 @@ func (h *URLHandler) ListURLs(c *gin.Context) {
 
 // GetUserURLs godoc
 // @Summary Get URLs created by authenticated user
-// @Description Get a paginated list of all URLs created by the authenticated user
+// @Description Get a paginated list of the personal URLs created by the authenticated user, or of the URLs of a workspace the user is a member of
 // @Tags URL Shortener
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param workspace_id query int false "List the URLs of this workspace instead"
 // @Param limit query int false "Number of results to return" default(50)
 // @Param offset query int false "Number of results to skip" default(0)
 // @Success 200 {object} domain.APIResponse{data=[]domain.URL} "List of user's URLs with pagination metadata"
 // @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 404 {object} domain.APIResponse "Workspace not found"
 // @Failure 500 {object} domain.APIResponse "Internal server error"
 // @Router /url/my-links [get]
 func (h *URLHandler) GetUserURLs(c *gin.Context) {
@@ -232,8 +673,20 @@ func (h *URLHandler) GetUserURLs(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	urls, err := h.service.GetURLsByUserID(userID.(int64), limit, offset)
+	var (
+		urls []*domain.URL
+		err  error
+	)
+	if workspaceID, _ := strconv.ParseInt(c.Query("workspace_id"), 10, 64); workspaceID != 0 {
+		urls, err = h.service.GetURLsByWorkspaceID(workspaceID, userID.(int64), limit, offset)
+	} else {
+		urls, err = h.service.GetURLsByUserID(userID.(int64), limit, offset)
+	}
 	if err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			utils.SendError(c, http.StatusNotFound, "Workspace not found", "WORKSPACE_NOT_FOUND", "No workspace with this ID exists or you are not a member")
+			return
+		}
 		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve URLs", "INTERNAL_ERROR", "An unexpected error occurred")
 		return
 	}
@@ -250,3 +703,113 @@ func (h *URLHandler) GetUserURLs(c *gin.Context) {
 
 	utils.SendSuccess(c, "User URLs retrieved successfully", urls, meta)
 }
+
+// getOwnedURL loads the URL for the alias path parameter, on the custom domain
+// named by the domain query parameter if any, and checks that the
+// authenticated user created it or has at least the workspace role required
+// in its workspace. If it returns false, an error response has already been
+// sent.
+func getOwnedURL(c *gin.Context, urlService service.URLService, required, action string) (*domain.URL, bool) {
+	alias := c.Param("alias")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return nil, false
+	}
+
+	// Get URL by alias
+	url, err := urlService.GetURLByAlias(c.Query("domain"), alias)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.SendError(c, http.StatusNotFound, "Short URL not found", "URL_NOT_FOUND", "The requested alias does not exist")
+			return nil, false
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		return nil, false
+	}
+
+	// Check the user's rights on this URL
+	if err := urlService.AuthorizeURL(url, userID.(int64), required); err != nil {
+		if errors.Is(err, domain.ErrWorkspaceForbidden) {
+			utils.SendError(c, http.StatusForbidden, "You don't have permission to "+action+" this URL", "FORBIDDEN", "You are not the owner of this URL or a member of its workspace with a role that allows this")
+			return nil, false
+		}
+		utils.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL", "INTERNAL_ERROR", "An unexpected error occurred")
+		return nil, false
+	}
+
+	return url, true
+}
+
+// newURLInfoResponse builds the detailed info response for a URL
+func (h *URLHandler) newURLInfoResponse(url *domain.URL) domain.URLInfoResponse {
+	return domain.URLInfoResponse{
+		Alias:              url.Alias,
+		Domain:             url.Domain,
+		ShortURL:           h.shortURL(url),
+		OriginalURL:        url.OriginalURL,
+		UserID:             url.UserID,
+		WorkspaceID:        url.WorkspaceID,
+		ClickCount:         url.ClickCount,
+		BotClickCount:      url.BotClickCount,
+		UniqueVisitors:     url.UniqueVisitors,
+		RedirectType:       url.RedirectType,
+		PasswordProtected:  url.PasswordHash != "",
+		Title:              url.Title,
+		ForcePreview:       url.ForcePreview,
+		GeoRules:           url.GeoRules,
+		DeviceRules:        url.DeviceRules,
+		Variants:           url.Variants,
+		QueryPolicy:        url.QueryPolicy,
+		OGTitle:            url.OGTitle,
+		OGDescription:      url.OGDescription,
+		OGImage:            url.OGImage,
+		ExpiresAt:          url.ExpiresAt,
+		MaxClicks:          url.MaxClicks,
+		ExpiredRedirectURL: url.ExpiredRedirectURL,
+		CreatedAt:          url.CreatedAt,
+		UpdatedAt:          url.UpdatedAt,
+	}
+}
+
+// shortURL returns the short URL of a URL, on its custom domain if it has one.
+// Custom domains are served with the scheme of BASE_URL.
+func (h *URLHandler) shortURL(url *domain.URL) string {
+	if url.Domain == "" {
+		return h.baseURL + "/" + url.Alias
+	}
+	scheme, _, _ := strings.Cut(h.baseURL, "://")
+	return scheme + "://" + url.Domain + "/" + url.Alias
+}
+
+// isValidationError reports whether err is caused by invalid user input
+func isValidationError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidURL) ||
+		errors.Is(err, domain.ErrURLTooLong) ||
+		errors.Is(err, domain.ErrInvalidAlias) ||
+		errors.Is(err, domain.ErrAliasTooLong) ||
+		errors.Is(err, domain.ErrPrivateURL) ||
+		errors.Is(err, domain.ErrExpiryInPast) ||
+		errors.Is(err, domain.ErrInvalidMaxClicks) ||
+		errors.Is(err, domain.ErrMaxClicksReached) ||
+		errors.Is(err, domain.ErrSetAndClear) ||
+		errors.Is(err, domain.ErrInvalidRedirectType) ||
+		errors.Is(err, domain.ErrInvalidLinkPassword) ||
+		errors.Is(err, domain.ErrTitleTooLong) ||
+		errors.Is(err, domain.ErrInvalidGeoRule) ||
+		errors.Is(err, domain.ErrTooManyGeoRules) ||
+		errors.Is(err, domain.ErrInvalidDeviceRule) ||
+		errors.Is(err, domain.ErrDuplicateDeviceRule) ||
+		errors.Is(err, domain.ErrInvalidVariant) ||
+		errors.Is(err, domain.ErrInvalidVariantCount) ||
+		errors.Is(err, domain.ErrDuplicateVariant) ||
+		errors.Is(err, domain.ErrInvalidQueryPolicy) ||
+		errors.Is(err, domain.ErrInvalidUTMValue) ||
+		errors.Is(err, domain.ErrUTMPresetNotFound) ||
+		errors.Is(err, domain.ErrOGDescriptionTooLong) ||
+		errors.Is(err, domain.ErrInvalidHostname) ||
+		errors.Is(err, domain.ErrDomainNotFound) ||
+		errors.Is(err, domain.ErrDomainNotVerified) ||
+		errors.Is(err, domain.ErrWorkspaceNotFound)
+}
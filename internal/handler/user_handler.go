@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
+	"github.com/Faleeeee/URL_Shortener/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler handles user settings and UTM preset HTTP requests
+type UserHandler struct {
+	userService *service.UserService
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// GetSettings godoc
+// @Summary Get user settings
+// @Description Get the defaults applied to new short URLs of the authenticated user
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.APIResponse{data=domain.UserSettings} "User settings"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 404 {object} domain.APIResponse "User not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /user/settings [get]
+func (h *UserHandler) GetSettings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	settings, err := h.userService.GetSettings(userID.(int64))
+	if err != nil {
+		h.sendSettingsError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Settings retrieved successfully", settings, nil)
+}
+
+// UpdateSettings godoc
+// @Summary Update user settings
+// @Description Change the defaults applied to new short URLs of the authenticated user. Only the fields present in the request body are changed; existing URLs keep their settings.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.UpdateUserSettingsRequest true "Settings to change"
+// @Success 200 {object} domain.APIResponse{data=domain.UserSettings} "Updated user settings"
+// @Failure 400 {object} domain.APIResponse "Invalid request or validation error"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 404 {object} domain.APIResponse "User not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /user/settings [patch]
+func (h *UserHandler) UpdateSettings(c *gin.Context) {
+	var req domain.UpdateUserSettingsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	settings, err := h.userService.UpdateSettings(userID.(int64), &req)
+	if err != nil {
+		h.sendSettingsError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Settings updated successfully", settings, nil)
+}
+
+// ListUTMPresets godoc
+// @Summary List UTM presets
+// @Description List the saved UTM presets of the authenticated user, ordered by name
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.APIResponse{data=[]domain.UTMPreset} "UTM presets"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /user/utm-presets [get]
+func (h *UserHandler) ListUTMPresets(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	presets, err := h.userService.ListUTMPresets(userID.(int64))
+	if err != nil {
+		h.sendSettingsError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "UTM presets retrieved successfully", presets, nil)
+}
+
+// SaveUTMPreset godoc
+// @Summary Create or replace a UTM preset
+// @Description Save a named set of UTM parameters that can be applied to new short URLs with utm_preset. Values are stored in canonical form: trimmed, lower-cased, with inner whitespace replaced by underscores. Saving an existing name replaces its parameters.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Preset name"
+// @Param request body domain.UTMParams true "UTM parameters"
+// @Success 200 {object} domain.APIResponse{data=domain.UTMPreset} "Saved UTM preset"
+// @Failure 400 {object} domain.APIResponse "Invalid request or validation error"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /user/utm-presets/{name} [put]
+func (h *UserHandler) SaveUTMPreset(c *gin.Context) {
+	var req domain.UTMParams
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	preset, err := h.userService.SaveUTMPreset(userID.(int64), c.Param("name"), req)
+	if err != nil {
+		h.sendSettingsError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "UTM preset saved successfully", preset, nil)
+}
+
+// DeleteUTMPreset godoc
+// @Summary Delete a UTM preset
+// @Description Delete a saved UTM preset. Short URLs created with it keep their UTM parameters.
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Preset name"
+// @Success 200 {object} domain.APIResponse "UTM preset deleted"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 404 {object} domain.APIResponse "UTM preset not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /user/utm-presets/{name} [delete]
+func (h *UserHandler) DeleteUTMPreset(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	if err := h.userService.DeleteUTMPreset(userID.(int64), c.Param("name")); err != nil {
+		h.sendSettingsError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "UTM preset deleted successfully", nil, nil)
+}
+
+// sendSettingsError maps a user settings or UTM preset error to an error
+// response
+func (h *UserHandler) sendSettingsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRedirectType),
+		errors.Is(err, domain.ErrInvalidUTMPresetName),
+		errors.Is(err, domain.ErrInvalidUTMValue),
+		errors.Is(err, domain.ErrEmptyUTM):
+		utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, domain.ErrUserNotFound):
+		utils.SendError(c, http.StatusNotFound, "User not found", "USER_NOT_FOUND", "The authenticated user no longer exists")
+	case errors.Is(err, domain.ErrUTMPresetNotFound):
+		utils.SendError(c, http.StatusNotFound, "UTM preset not found", "UTM_PRESET_NOT_FOUND", "No UTM preset with this name exists")
+	default:
+		utils.SendError(c, http.StatusInternalServerError, "Failed to process settings", "INTERNAL_ERROR", "An unexpected error occurred")
+	}
+}
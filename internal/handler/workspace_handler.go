@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
+	"github.com/Faleeeee/URL_Shortener/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHandler handles workspace and membership HTTP requests
+type WorkspaceHandler struct {
+	workspaceService *service.WorkspaceService
+}
+
+// NewWorkspaceHandler creates a new workspace handler
+func NewWorkspaceHandler(workspaceService *service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+	}
+}
+
+// CreateWorkspace godoc
+// @Summary Create a workspace
+// @Description Create a workspace to share short URLs with a team. The authenticated user becomes its first owner.
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.CreateWorkspaceRequest true "Workspace to create"
+// @Success 200 {object} domain.APIResponse{data=domain.Workspace} "Workspace created"
+// @Failure 400 {object} domain.APIResponse "Invalid workspace name"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /workspaces [post]
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req domain.CreateWorkspaceRequest
+
+	// Validate request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	ws, err := h.workspaceService.Create(userID.(int64), req.Name)
+	if err != nil {
+		h.sendWorkspaceError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Workspace created successfully", ws, nil)
+}
+
+// ListWorkspaces godoc
+// @Summary List workspaces
+// @Description Get the workspaces the authenticated user is a member of, with the user's role in each
+// @Tags Workspaces
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.APIResponse{data=[]domain.Workspace} "Workspaces"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /workspaces [get]
+func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return
+	}
+
+	workspaces, err := h.workspaceService.List(userID.(int64))
+	if err != nil {
+		h.sendWorkspaceError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Workspaces retrieved successfully", workspaces, nil)
+}
+
+// DeleteWorkspace godoc
+// @Summary Delete a workspace
+// @Description Delete a workspace and its memberships (owners only). Workspaces that still have short URLs cannot be deleted.
+// @Tags Workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Success 200 {object} domain.APIResponse "Workspace deleted"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not an owner"
+// @Failure 404 {object} domain.APIResponse "Workspace not found"
+// @Failure 409 {object} domain.APIResponse "Workspace still has short URLs"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /workspaces/{id} [delete]
+func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+	userID, workspaceID, ok := h.workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.Delete(workspaceID, userID); err != nil {
+		h.sendWorkspaceError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Workspace deleted successfully", nil, nil)
+}
+
+// ListMembers godoc
+// @Summary List workspace members
+// @Description Get the members of a workspace and their roles (members only)
+// @Tags Workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Success 200 {object} domain.APIResponse{data=[]domain.WorkspaceMember} "Workspace members"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 404 {object} domain.APIResponse "Workspace not found"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /workspaces/{id}/members [get]
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	userID, workspaceID, ok := h.workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.workspaceService.ListMembers(workspaceID, userID)
+	if err != nil {
+		h.sendWorkspaceError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Workspace members retrieved successfully", members, nil)
+}
+
+// AddMember godoc
+// @Summary Add a workspace member
+// @Description Add a user to a workspace as owner, editor or viewer (owners only). Viewers see the workspace's URLs and their stats, editors also create, change and delete them, and owners also manage members.
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Param request body domain.AddWorkspaceMemberRequest true "User and role"
+// @Success 200 {object} domain.APIResponse{data=domain.WorkspaceMember} "Member added"
+// @Failure 400 {object} domain.APIResponse "Invalid role"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not an owner"
+// @Failure 404 {object} domain.APIResponse "Workspace or user not found"
+// @Failure 409 {object} domain.APIResponse "User is already a member"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /workspaces/{id}/members [post]
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	var req domain.AddWorkspaceMemberRequest
+
+	// Validate request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, workspaceID, ok := h.workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.workspaceService.AddMember(workspaceID, userID, &req)
+	if err != nil {
+		h.sendWorkspaceError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Workspace member added successfully", member, nil)
+}
+
+// UpdateMember godoc
+// @Summary Change a workspace member's role
+// @Description Change the role of a workspace member (owners only). The last owner cannot step down.
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Param user_id path int true "User ID of the member"
+// @Param request body domain.UpdateWorkspaceMemberRequest true "New role"
+// @Success 200 {object} domain.APIResponse "Role changed"
+// @Failure 400 {object} domain.APIResponse "Invalid role"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not an owner"
+// @Failure 404 {object} domain.APIResponse "Workspace or member not found"
+// @Failure 409 {object} domain.APIResponse "Last owner of the workspace"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /workspaces/{id}/members/{user_id} [patch]
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	var req domain.UpdateWorkspaceMemberRequest
+
+	// Validate request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, workspaceID, ok := h.workspaceParams(c)
+	if !ok {
+		return
+	}
+	memberID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.UpdateMember(workspaceID, userID, memberID, req.Role); err != nil {
+		h.sendWorkspaceError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Workspace member updated successfully", nil, nil)
+}
+
+// RemoveMember godoc
+// @Summary Remove a workspace member
+// @Description Remove a member from a workspace (owners only), or leave a workspace by passing one's own user ID. The last owner cannot leave. URLs the member created stay in the workspace.
+// @Tags Workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Param user_id path int true "User ID of the member"
+// @Success 200 {object} domain.APIResponse "Member removed"
+// @Failure 401 {object} domain.APIResponse "Unauthorized"
+// @Failure 403 {object} domain.APIResponse "Forbidden - not an owner"
+// @Failure 404 {object} domain.APIResponse "Workspace or member not found"
+// @Failure 409 {object} domain.APIResponse "Last owner of the workspace"
+// @Failure 500 {object} domain.APIResponse "Internal server error"
+// @Router /workspaces/{id}/members/{user_id} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID, workspaceID, ok := h.workspaceParams(c)
+	if !ok {
+		return
+	}
+	memberID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(workspaceID, userID, memberID); err != nil {
+		h.sendWorkspaceError(c, err)
+		return
+	}
+
+	utils.SendSuccess(c, "Workspace member removed successfully", nil, nil)
+}
+
+// workspaceParams returns the authenticated user and the workspace ID path
+// parameter. If it returns false, an error response has already been sent.
+func (h *WorkspaceHandler) workspaceParams(c *gin.Context) (int64, int64, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendError(c, http.StatusUnauthorized, "User not authenticated", "UNAUTHORIZED", "Missing or invalid authentication token")
+		return 0, 0, false
+	}
+
+	workspaceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+
+	return userID.(int64), workspaceID, true
+}
+
+// parseIDParam parses a numeric ID path parameter. If it returns false, an
+// error response has already been sent.
+func parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		utils.SendError(c, http.StatusBadRequest, "Invalid "+name, "INVALID_REQUEST", "The "+name+" path parameter must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
+
+// sendWorkspaceError maps a workspace error to an error response
+func (h *WorkspaceHandler) sendWorkspaceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidWorkspaceName),
+		errors.Is(err, domain.ErrInvalidWorkspaceRole):
+		utils.SendError(c, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, domain.ErrWorkspaceForbidden):
+		utils.SendError(c, http.StatusForbidden, "You don't have permission to manage this workspace", "FORBIDDEN", err.Error())
+	case errors.Is(err, domain.ErrWorkspaceNotFound):
+		utils.SendError(c, http.StatusNotFound, "Workspace not found", "WORKSPACE_NOT_FOUND", "No workspace with this ID exists or you are not a member")
+	case errors.Is(err, domain.ErrWorkspaceMemberNotFound):
+		utils.SendError(c, http.StatusNotFound, "Member not found", "MEMBER_NOT_FOUND", err.Error())
+	case errors.Is(err, domain.ErrUserNotFound):
+		utils.SendError(c, http.StatusNotFound, "User not found", "USER_NOT_FOUND", "No user with this username exists")
+	case errors.Is(err, domain.ErrWorkspaceMemberExists),
+		errors.Is(err, domain.ErrLastWorkspaceOwner),
+		errors.Is(err, domain.ErrWorkspaceInUse):
+		utils.SendError(c, http.StatusConflict, err.Error(), "WORKSPACE_CONFLICT", err.Error())
+	default:
+		utils.SendError(c, http.StatusInternalServerError, "Failed to process workspace", "INTERNAL_ERROR", "An unexpected error occurred")
+	}
+}
@@ -0,0 +1,228 @@
+package hll
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+// hashes returns n distinct, uniformly distributed hashes starting at the
+// seed-th element of a SplitMix64 sequence, so runs are reproducible
+func hashes(seed, n uint64) []uint64 {
+	out := make([]uint64, n)
+	for i := range out {
+		z := (seed + uint64(i) + 1) * 0x9e3779b97f4a7c15
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		out[i] = z ^ (z >> 31)
+	}
+	return out
+}
+
+// relativeError returns how far an estimate is from the true count
+func relativeError(estimate int64, n uint64) float64 {
+	return math.Abs(float64(estimate)-float64(n)) / float64(n)
+}
+
+// maxRelativeError allows about three standard errors at Precision 12
+const maxRelativeError = 0.05
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name string
+		n    uint64
+	}{
+		{name: "ten", n: 10},
+		{name: "thousand", n: 1000},
+		{name: "linear counting boundary", n: 10000},
+		{name: "hundred thousand", n: 100000},
+		{name: "million", n: 1000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			for _, h := range hashes(0, tt.n) {
+				s.Add(h)
+			}
+			if got := s.Estimate(); relativeError(got, tt.n) > maxRelativeError {
+				t.Errorf("Estimate() = %d for %d elements, error %.2f%% exceeds %.0f%%",
+					got, tt.n, 100*relativeError(got, tt.n), 100*maxRelativeError)
+			}
+		})
+	}
+}
+
+func TestEstimateEmpty(t *testing.T) {
+	if got := New().Estimate(); got != 0 {
+		t.Errorf("Estimate() of an empty sketch = %d, want 0", got)
+	}
+}
+
+func TestAddDuplicates(t *testing.T) {
+	s := New()
+	for i := 0; i < 100; i++ {
+		for _, h := range hashes(0, 500) {
+			s.Add(h)
+		}
+	}
+	if got := s.Estimate(); relativeError(got, 500) > maxRelativeError {
+		t.Errorf("Estimate() = %d after adding 500 elements 100 times", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name          string
+		seedA, countA uint64
+		seedB, countB uint64
+		want          uint64
+	}{
+		{name: "disjoint", seedA: 0, countA: 20000, seedB: 20000, countB: 30000, want: 50000},
+		{name: "overlapping", seedA: 0, countA: 20000, seedB: 10000, countB: 20000, want: 30000},
+		{name: "identical", seedA: 0, countA: 20000, seedB: 0, countB: 20000, want: 20000},
+		{name: "subset", seedA: 0, countA: 20000, seedB: 5000, countB: 100, want: 20000},
+		{name: "into empty", seedA: 0, countA: 0, seedB: 0, countB: 20000, want: 20000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, union := New(), New(), New()
+			for _, h := range hashes(tt.seedA, tt.countA) {
+				a.Add(h)
+				union.Add(h)
+			}
+			for _, h := range hashes(tt.seedB, tt.countB) {
+				b.Add(h)
+				union.Add(h)
+			}
+
+			a.Merge(b)
+			if a.registers != union.registers {
+				t.Error("merged sketch differs from a sketch of the union")
+			}
+			if got := a.Estimate(); relativeError(got, tt.want) > maxRelativeError {
+				t.Errorf("Estimate() after merge = %d, want about %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeIsCommutative(t *testing.T) {
+	a, b := New(), New()
+	for _, h := range hashes(0, 3000) {
+		a.Add(h)
+	}
+	for _, h := range hashes(2000, 3000) {
+		b.Add(h)
+	}
+
+	ab, ba := New(), New()
+	ab.Merge(a)
+	ab.Merge(b)
+	ba.Merge(b)
+	ba.Merge(a)
+	if ab.registers != ba.registers {
+		t.Error("merging in a different order gave a different sketch")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		n    uint64
+	}{
+		{name: "empty", n: 0},
+		{name: "sparse", n: 50},
+		{name: "dense", n: 200000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			for _, h := range hashes(7, tt.n) {
+				s.Add(h)
+			}
+
+			data, err := s.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() error = %v", err)
+			}
+			if len(data) != 1+registerCount || data[0] != Precision {
+				t.Fatalf("MarshalBinary() wrote %d bytes with precision %d", len(data), data[0])
+			}
+
+			decoded, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded.registers != s.registers {
+				t.Error("decoded sketch differs from the original")
+			}
+
+			again, _ := decoded.MarshalBinary()
+			if !bytes.Equal(again, data) {
+				t.Error("re-encoding a decoded sketch changed its bytes")
+			}
+		})
+	}
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	for _, data := range [][]byte{nil, {}} {
+		s, err := Decode(data)
+		if err != nil {
+			t.Fatalf("Decode(%v) error = %v", data, err)
+		}
+		if got := s.Estimate(); got != 0 {
+			t.Errorf("Decode(%v).Estimate() = %d, want 0", data, got)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid, _ := New().MarshalBinary()
+
+	withByte := func(i int, b byte) []byte {
+		data := bytes.Clone(valid)
+		data[i] = b
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "precision only", data: []byte{Precision}},
+		{name: "truncated", data: valid[:len(valid)-1]},
+		{name: "trailing bytes", data: append(bytes.Clone(valid), 0)},
+		{name: "lower precision", data: withByte(0, Precision-1)},
+		{name: "higher precision", data: withByte(0, Precision+1)},
+		{name: "register out of range", data: withByte(1+registerCount/2, maxRank+1)},
+		{name: "register overflow", data: withByte(len(valid)-1, 0xff)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.data); !errors.Is(err, ErrInvalidSketch) {
+				t.Errorf("Decode() error = %v, want ErrInvalidSketch", err)
+			}
+		})
+	}
+}
+
+func TestAddMaxRank(t *testing.T) {
+	s := New()
+	// All bits after the register index zero: the rank is capped by the
+	// sentinel bit
+	s.Add(0)
+	if s.registers[0] != maxRank {
+		t.Errorf("register = %d for an all-zero hash, want %d", s.registers[0], maxRank)
+	}
+
+	data, _ := s.MarshalBinary()
+	if _, err := Decode(data); err != nil {
+		t.Errorf("Decode() rejected a sketch with a maximal register: %v", err)
+	}
+}
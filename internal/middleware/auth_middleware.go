@@ -1,19 +1,38 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
 	"github.com/Faleeeee/URL_Shortener/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware creates an authentication middleware
-func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+// apiKeyScheme is the Authorization scheme of API keys, as in
+// "Authorization: ApiKey usk_..."
+const apiKeyScheme = "ApiKey "
+
+// AuthMiddleware creates an authentication middleware. Requests carry either
+// a JWT, or an API key in the X-API-Key header or with the ApiKey
+// Authorization scheme. API keys are only accepted when scopes are given, and
+// must have all of them.
+func AuthMiddleware(jwtManager *utils.JWTManager, apiKeys *service.APIKeyService, scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
+
+		if rawKey := c.GetHeader("X-API-Key"); rawKey != "" || strings.HasPrefix(authHeader, apiKeyScheme) {
+			if rawKey == "" {
+				rawKey = strings.TrimPrefix(authHeader, apiKeyScheme)
+			}
+			authenticateAPIKey(c, apiKeys, rawKey, scopes)
+			return
+		}
+
 		if authHeader == "" {
 			utils.SendError(c, http.StatusUnauthorized, "Authorization header required", "AUTH_REQUIRED", "Missing Authorization header")
 			c.Abort()
@@ -53,7 +72,44 @@ func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 		// Set user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
 
 		c.Next()
 	}
 }
+
+// authenticateAPIKey authenticates a request made with an API key that must
+// have every scope in scopes. API keys act as their owner, but never with the
+// owner's site-wide role.
+func authenticateAPIKey(c *gin.Context, apiKeys *service.APIKeyService, rawKey string, scopes []string) {
+	key, err := apiKeys.Authenticate(strings.TrimSpace(rawKey))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAPIKey) {
+			utils.SendError(c, http.StatusUnauthorized, "Invalid API key", "INVALID_API_KEY", "The provided API key is invalid or has been revoked")
+		} else {
+			utils.SendError(c, http.StatusInternalServerError, "Failed to authenticate", "INTERNAL_ERROR", "An unexpected error occurred")
+		}
+		c.Abort()
+		return
+	}
+
+	if len(scopes) == 0 {
+		utils.SendError(c, http.StatusForbidden, "API keys are not accepted here", "API_KEY_NOT_ALLOWED", "This endpoint requires signing in")
+		c.Abort()
+		return
+	}
+	for _, scope := range scopes {
+		if !key.HasScope(scope) {
+			utils.SendError(c, http.StatusForbidden, "Insufficient API key scope", "INSUFFICIENT_SCOPE", "This endpoint requires the "+scope+" scope")
+			c.Abort()
+			return
+		}
+	}
+
+	// Set user information in context
+	c.Set("user_id", key.UserID)
+	c.Set("username", key.Username)
+	c.Set("api_key_id", key.ID)
+
+	c.Next()
+}
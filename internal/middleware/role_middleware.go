@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
+	"github.com/Faleeeee/URL_Shortener/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole creates a middleware that only lets users with at least the
+// given role through. It must run after AuthMiddleware. The role is looked
+// up on every request rather than taken from the token, so demoted users
+// lose access right away.
+func RequireRole(users *service.UserService, required string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, err := users.GetRole(c.GetInt64("user_id"))
+		if err != nil {
+			if err == domain.ErrUserNotFound {
+				utils.SendError(c, http.StatusUnauthorized, "User not found", "AUTH_REQUIRED", "The user of this token no longer exists")
+			} else {
+				utils.SendError(c, http.StatusInternalServerError, "Failed to check role", "INTERNAL_ERROR", "An unexpected error occurred")
+			}
+			c.Abort()
+			return
+		}
+
+		if !domain.RoleAllows(role, required) {
+			utils.SendError(c, http.StatusForbidden, "Insufficient role", "FORBIDDEN", "This endpoint requires the "+required+" role")
+			c.Abort()
+			return
+		}
+
+		c.Set("role", role)
+		c.Next()
+	}
+}
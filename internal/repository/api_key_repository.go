@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Faleeeee/URL_Shortener/internal/database"
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// APIKeyRepository handles API key data access
+type APIKeyRepository struct {
+	db *database.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *database.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// apiKeyColumns is the column list scanned by scanAPIKey
+const apiKeyColumns = `k.id, k.user_id, u.username, k.name, k.prefix, k.key_hash, k.scopes, k.last_used_at, k.revoked_at, k.created_at`
+
+// scanAPIKey scans a row selected with apiKeyColumns into an API key
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Username,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&lastUsedAt,
+		&revokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
+
+// Create stores a new API key
+func (r *APIKeyRepository) Create(key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(query, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes)).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+// FindActiveByHash retrieves the unrevoked API key with a key hash, along with
+// the username of its owner
+func (r *APIKeyRepository) FindActiveByHash(keyHash string) (*domain.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL
+	`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, keyHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// FindByUserID retrieves all API keys of a user, revoked ones included,
+// newest first
+func (r *APIKeyRepository) FindByUserID(userID int64) ([]*domain.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.user_id = $1
+		ORDER BY k.created_at DESC, k.id DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke disables an unrevoked API key of a user
+func (r *APIKeyRepository) Revoke(userID, id int64) error {
+	result, err := r.db.Exec(
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		id, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// TouchLastUsed records that an API key was just used
+func (r *APIKeyRepository) TouchLastUsed(id int64) error {
+	if _, err := r.db.Exec(`UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to record API key use: %w", err)
+	}
+	return nil
+}
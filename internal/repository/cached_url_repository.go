@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"container/list"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+)
+
+const (
+	DefaultURLCacheTTL         = 30 * time.Second
+	DefaultURLCacheNegativeTTL = 5 * time.Second
+)
+
+// urlCacheShards is the number of independently locked LRU shards, so
+// concurrent redirects for different aliases rarely contend on one mutex
+const urlCacheShards = 16
+
+// cachedURLRepository is a URLRepository decorator that caches FindByAlias
+// results in a sharded, size-bounded LRU. Misses are cached too, for a shorter
+// time, so scans of random aliases do not all reach the database. Entries are
+// invalidated by every mutation made through the decorator; with several
+// instances, changes made elsewhere show up once the entry expires.
+//
+// Cached URLs can lag behind the background click writer, so their click
+// counters may be up to one TTL old. Click caps are still enforced by
+// ClaimClick in the database.
+type cachedURLRepository struct {
+	URLRepository
+	shards      [urlCacheShards]*urlCacheShard
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	// keys maps the ID of each cached URL to its cache key, so SoftDelete can
+	// invalidate by ID
+	keys sync.Map
+}
+
+type urlCacheShard struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element // by cache key, see urlCacheKey
+	lru      *list.List               // front is most recently used
+	// version is bumped on every invalidation, so a lookup that raced with a
+	// mutation does not cache the row it read before the change
+	version uint64
+
+	// added and removed keep the repository's ID index in step with the shard
+	added   func(*urlCacheEntry)
+	removed func(*urlCacheEntry)
+}
+
+type urlCacheEntry struct {
+	key       string
+	url       *domain.URL // nil for a cached miss
+	expiresAt time.Time
+}
+
+// NewCachedURLRepository wraps repo with an LRU cache of at most size URLs.
+// A size of zero or less disables caching and returns repo unchanged.
+func NewCachedURLRepository(repo URLRepository, size int, ttl, negativeTTL time.Duration) URLRepository {
+	if size <= 0 {
+		return repo
+	}
+	if ttl <= 0 {
+		ttl = DefaultURLCacheTTL
+	}
+	if negativeTTL <= 0 {
+		negativeTTL = DefaultURLCacheNegativeTTL
+	}
+
+	capacity := size / urlCacheShards
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	r := &cachedURLRepository{
+		URLRepository: repo,
+		ttl:           ttl,
+		negativeTTL:   negativeTTL,
+	}
+	for i := range r.shards {
+		r.shards[i] = &urlCacheShard{
+			capacity: capacity,
+			entries:  make(map[string]*list.Element),
+			lru:      list.New(),
+			added:    r.index,
+			removed:  r.forget,
+		}
+	}
+
+	return r
+}
+
+// Create stores a new URL and forgets any cached miss for its alias
+func (r *cachedURLRepository) Create(url *domain.URL) error {
+	err := r.URLRepository.Create(url)
+	r.invalidate(urlCacheKey(url.DomainID, url.Alias))
+	return err
+}
+
+// FindByAlias returns a URL from the cache, or loads and caches it. Rows are
+// cached including soft-deleted ones, and filtered by includeDeleted on the
+// way out. Callers get their own copy, so they may modify it freely.
+func (r *cachedURLRepository) FindByAlias(domainID int64, alias string, includeDeleted bool) (*domain.URL, error) {
+	key := urlCacheKey(domainID, alias)
+	shard := r.shard(key)
+
+	url, found, version := shard.get(key, time.Now())
+	if !found {
+		var err error
+		url, err = r.URLRepository.FindByAlias(domainID, alias, true)
+		switch {
+		case err == nil:
+			shard.put(key, url, time.Now().Add(r.ttl), version)
+		case err == ErrNotFound:
+			shard.put(key, nil, time.Now().Add(r.negativeTTL), version)
+		default:
+			return nil, err
+		}
+	}
+
+	if url == nil || (url.DeletedAt != nil && !includeDeleted) {
+		return nil, ErrNotFound
+	}
+
+	return cloneURL(url), nil
+}
+
+// Update persists a URL and invalidates its cache entry
+func (r *cachedURLRepository) Update(url *domain.URL) error {
+	err := r.URLRepository.Update(url)
+	r.invalidate(urlCacheKey(url.DomainID, url.Alias))
+	return err
+}
+
+// SoftDelete marks a URL as deleted and invalidates its cache entry
+func (r *cachedURLRepository) SoftDelete(id int64) error {
+	err := r.URLRepository.SoftDelete(id)
+	if key, ok := r.keys.Load(id); ok {
+		r.invalidate(key.(string))
+		return err
+	}
+
+	// The URL is not cached, but a lookup may be loading it right now. No
+	// key is empty, so this only bumps every shard's version.
+	for _, shard := range r.shards {
+		shard.remove("")
+	}
+	return err
+}
+
+// Purge permanently removes a URL and invalidates its cache entry
+func (r *cachedURLRepository) Purge(domainID int64, alias string) error {
+	err := r.URLRepository.Purge(domainID, alias)
+	r.invalidate(urlCacheKey(domainID, alias))
+	return err
+}
+
+// ClaimClick counts a click in the database. When the click is refused, the
+// cached URL is invalidated so the next lookup sees it as expired.
+func (r *cachedURLRepository) ClaimClick(domainID int64, alias string) (bool, error) {
+	claimed, err := r.URLRepository.ClaimClick(domainID, alias)
+	if err == nil && !claimed {
+		r.invalidate(urlCacheKey(domainID, alias))
+	}
+	return claimed, err
+}
+
+// ForgetWorkspace invalidates every cached URL of a workspace
+func (r *cachedURLRepository) ForgetWorkspace(workspaceID int64) {
+	for _, shard := range r.shards {
+		shard.removeIf(func(url *domain.URL) bool {
+			return url != nil && url.WorkspaceID == workspaceID
+		})
+	}
+}
+
+// urlCacheKey identifies a URL in the cache by domain and alias
+func urlCacheKey(domainID int64, alias string) string {
+	return strconv.FormatInt(domainID, 10) + "/" + alias
+}
+
+func (r *cachedURLRepository) shard(key string) *urlCacheShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return r.shards[h.Sum32()%urlCacheShards]
+}
+
+func (r *cachedURLRepository) invalidate(key string) {
+	r.shard(key).remove(key)
+}
+
+// index records the cache key of a newly cached URL under its ID
+func (r *cachedURLRepository) index(entry *urlCacheEntry) {
+	if entry.url != nil {
+		r.keys.Store(entry.url.ID, entry.key)
+	}
+}
+
+// forget drops the ID index entry of an evicted or invalidated URL
+func (r *cachedURLRepository) forget(entry *urlCacheEntry) {
+	if entry.url != nil {
+		r.keys.Delete(entry.url.ID)
+	}
+}
+
+// get returns the cached URL for a key, and whether there was a fresh entry.
+// The shard version is returned for a following put.
+func (s *urlCacheShard) get(key string, now time.Time) (*domain.URL, bool, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false, s.version
+	}
+
+	entry := elem.Value.(*urlCacheEntry)
+	if now.After(entry.expiresAt) {
+		return nil, false, s.version
+	}
+
+	s.lru.MoveToFront(elem)
+	return entry.url, true, s.version
+}
+
+// put caches a URL, or a miss when url is nil, unless the shard was
+// invalidated since version was read. The least recently used entry is
+// evicted when the shard is full.
+func (s *urlCacheShard) put(key string, url *domain.URL, expiresAt time.Time, version uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.version != version {
+		return
+	}
+
+	entry := &urlCacheEntry{key: key, url: url, expiresAt: expiresAt}
+	if elem, ok := s.entries[key]; ok {
+		s.removed(elem.Value.(*urlCacheEntry))
+		elem.Value = entry
+		s.lru.MoveToFront(elem)
+	} else {
+		s.entries[key] = s.lru.PushFront(entry)
+	}
+	s.added(entry)
+
+	if s.lru.Len() > s.capacity {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		evicted := oldest.Value.(*urlCacheEntry)
+		delete(s.entries, evicted.key)
+		s.removed(evicted)
+	}
+}
+
+// remove drops the entry for a key, if any, and bumps the shard version
+func (s *urlCacheShard) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.version++
+
+	if elem, ok := s.entries[key]; ok {
+		s.lru.Remove(elem)
+		delete(s.entries, key)
+		s.removed(elem.Value.(*urlCacheEntry))
+	}
+}
+
+// removeIf drops the entries whose URL matches, and bumps the shard version
+func (s *urlCacheShard) removeIf(match func(*domain.URL) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.version++
+
+	for key, elem := range s.entries {
+		entry := elem.Value.(*urlCacheEntry)
+		if match(entry.url) {
+			s.lru.Remove(elem)
+			delete(s.entries, key)
+			s.removed(entry)
+		}
+	}
+}
+
+// cloneURL returns a copy of a URL that shares no pointers with the original
+func cloneURL(url *domain.URL) *domain.URL {
+	clone := *url
+	if url.DeletedAt != nil {
+		deletedAt := *url.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	if url.ExpiresAt != nil {
+		expiresAt := *url.ExpiresAt
+		clone.ExpiresAt = &expiresAt
+	}
+	if url.MaxClicks != nil {
+		maxClicks := *url.MaxClicks
+		clone.MaxClicks = &maxClicks
+	}
+	if url.GeoRules != nil {
+		clone.GeoRules = make([]domain.GeoRule, len(url.GeoRules))
+		for i, rule := range url.GeoRules {
+			clone.GeoRules[i] = domain.GeoRule{
+				Countries: append([]string(nil), rule.Countries...),
+				URL:       rule.URL,
+			}
+		}
+	}
+	if url.DeviceRules != nil {
+		clone.DeviceRules = append([]domain.DeviceRule(nil), url.DeviceRules...)
+	}
+	if url.Variants != nil {
+		clone.Variants = append([]domain.Variant(nil), url.Variants...)
+	}
+	return &clone
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+)
+
+// fakeURLRepository is an in-memory URLRepository. Methods the cache does not
+// decorate are left to the embedded nil interface and panic if called.
+type fakeURLRepository struct {
+	URLRepository
+
+	mu     sync.Mutex
+	urls   map[string]*domain.URL // by urlCacheKey
+	nextID int64
+	finds  int
+
+	// onFind, if set, runs after a lookup read its row and before it returns,
+	// to simulate a mutation racing with the lookup
+	onFind func()
+}
+
+func newFakeURLRepository() *fakeURLRepository {
+	return &fakeURLRepository{urls: make(map[string]*domain.URL)}
+}
+
+func (f *fakeURLRepository) Create(url *domain.URL) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := urlCacheKey(url.DomainID, url.Alias)
+	if _, ok := f.urls[key]; ok {
+		return ErrDuplicateAlias
+	}
+	f.nextID++
+	url.ID = f.nextID
+	f.urls[key] = cloneURL(url)
+	return nil
+}
+
+func (f *fakeURLRepository) FindByAlias(domainID int64, alias string, includeDeleted bool) (*domain.URL, error) {
+	f.mu.Lock()
+	f.finds++
+	url, ok := f.urls[urlCacheKey(domainID, alias)]
+	if ok {
+		url = cloneURL(url)
+	}
+	onFind := f.onFind
+	f.mu.Unlock()
+
+	if onFind != nil {
+		onFind()
+	}
+
+	if !ok || (url.DeletedAt != nil && !includeDeleted) {
+		return nil, ErrNotFound
+	}
+	return url, nil
+}
+
+func (f *fakeURLRepository) Update(url *domain.URL) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := urlCacheKey(url.DomainID, url.Alias)
+	if _, ok := f.urls[key]; !ok {
+		return ErrNotFound
+	}
+	f.urls[key] = cloneURL(url)
+	return nil
+}
+
+func (f *fakeURLRepository) SoftDelete(id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, url := range f.urls {
+		if url.ID == id && url.DeletedAt == nil {
+			now := time.Now()
+			url.DeletedAt = &now
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (f *fakeURLRepository) Purge(domainID int64, alias string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.urls, urlCacheKey(domainID, alias))
+	return nil
+}
+
+// findCount returns the number of lookups that reached the fake
+func (f *fakeURLRepository) findCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.finds
+}
+
+// newTestCache wraps a fake repository in a cache of size URLs
+func newTestCache(t *testing.T, size int) (*cachedURLRepository, *fakeURLRepository) {
+	t.Helper()
+
+	fake := newFakeURLRepository()
+	cached, ok := NewCachedURLRepository(fake, size, time.Minute, time.Second).(*cachedURLRepository)
+	if !ok {
+		t.Fatal("NewCachedURLRepository did not return a cache")
+	}
+	return cached, fake
+}
+
+// seed stores a URL in the fake, bypassing the cache
+func seed(t *testing.T, fake *fakeURLRepository, domainID int64, alias string) *domain.URL {
+	t.Helper()
+
+	url := &domain.URL{DomainID: domainID, Alias: alias, OriginalURL: "https://example.com/" + alias}
+	if err := fake.Create(url); err != nil {
+		t.Fatalf("failed to seed %q: %v", alias, err)
+	}
+	return url
+}
+
+// sameShardAliases returns n aliases on domain zero that share a cache shard
+func sameShardAliases(r *cachedURLRepository, n int) []string {
+	var aliases []string
+	target := r.shard(urlCacheKey(0, "a0"))
+	for i := 0; len(aliases) < n; i++ {
+		alias := fmt.Sprintf("a%d", i)
+		if r.shard(urlCacheKey(0, alias)) == target {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+func TestNewCachedURLRepositoryDisabled(t *testing.T) {
+	fake := newFakeURLRepository()
+	for _, size := range []int{0, -1} {
+		if got := NewCachedURLRepository(fake, size, time.Minute, time.Second); got != URLRepository(fake) {
+			t.Errorf("size %d: expected the repository to be returned unwrapped", size)
+		}
+	}
+}
+
+func TestCachedFindByAliasHit(t *testing.T) {
+	r, fake := newTestCache(t, 64)
+	seed(t, fake, 0, "abc")
+
+	for i := 0; i < 3; i++ {
+		url, err := r.FindByAlias(0, "abc", false)
+		if err != nil {
+			t.Fatalf("FindByAlias() error = %v", err)
+		}
+		if url.OriginalURL != "https://example.com/abc" {
+			t.Errorf("FindByAlias() = %q", url.OriginalURL)
+		}
+	}
+	if got := fake.findCount(); got != 1 {
+		t.Errorf("repository was queried %d times, want 1", got)
+	}
+}
+
+func TestCachedFindByAliasDomains(t *testing.T) {
+	r, fake := newTestCache(t, 64)
+	seed(t, fake, 0, "same")
+	seed(t, fake, 7, "same")
+
+	base, err := r.FindByAlias(0, "same", false)
+	if err != nil {
+		t.Fatalf("FindByAlias(0) error = %v", err)
+	}
+	custom, err := r.FindByAlias(7, "same", false)
+	if err != nil {
+		t.Fatalf("FindByAlias(7) error = %v", err)
+	}
+	if base.ID == custom.ID {
+		t.Error("the same alias on two domains returned the same URL")
+	}
+}
+
+func TestCachedNegativeTTL(t *testing.T) {
+	r, fake := newTestCache(t, 64)
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.FindByAlias(0, "missing", false); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("FindByAlias() error = %v, want ErrNotFound", err)
+		}
+	}
+	if got := fake.findCount(); got != 1 {
+		t.Errorf("repository was queried %d times for a cached miss, want 1", got)
+	}
+
+	key := urlCacheKey(0, "missing")
+	shard := r.shard(key)
+	now := time.Now()
+	if _, found, _ := shard.get(key, now.Add(r.negativeTTL/2)); !found {
+		t.Error("miss expired before the negative TTL")
+	}
+	if _, found, _ := shard.get(key, now.Add(r.negativeTTL+time.Millisecond)); found {
+		t.Error("miss was still cached after the negative TTL")
+	}
+}
+
+func TestCachedCreateForgetsMiss(t *testing.T) {
+	r, _ := newTestCache(t, 64)
+
+	if _, err := r.FindByAlias(0, "new", false); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FindByAlias() error = %v, want ErrNotFound", err)
+	}
+	if err := r.Create(&domain.URL{Alias: "new", OriginalURL: "https://example.com/new"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := r.FindByAlias(0, "new", false); err != nil {
+		t.Errorf("FindByAlias() after Create error = %v", err)
+	}
+}
+
+func TestCachedLRUEviction(t *testing.T) {
+	// Two entries per shard
+	r, fake := newTestCache(t, 2*urlCacheShards)
+	aliases := sameShardAliases(r, 3)
+	urls := make(map[string]*domain.URL)
+	for _, alias := range aliases {
+		urls[alias] = seed(t, fake, 0, alias)
+	}
+
+	mustFind := func(alias string) {
+		t.Helper()
+		if _, err := r.FindByAlias(0, alias, false); err != nil {
+			t.Fatalf("FindByAlias(%q) error = %v", alias, err)
+		}
+	}
+
+	mustFind(aliases[0])
+	mustFind(aliases[1])
+	mustFind(aliases[0]) // aliases[1] is now least recently used
+	mustFind(aliases[2]) // evicts aliases[1]
+
+	shard := r.shard(urlCacheKey(0, aliases[0]))
+	if got := shard.lru.Len(); got != 2 {
+		t.Errorf("shard holds %d entries, want 2", got)
+	}
+	for alias, wantCached := range map[string]bool{aliases[0]: true, aliases[1]: false, aliases[2]: true} {
+		if _, cached := shard.entries[urlCacheKey(0, alias)]; cached != wantCached {
+			t.Errorf("%q cached = %v, want %v", alias, cached, wantCached)
+		}
+		if _, indexed := r.keys.Load(urls[alias].ID); indexed != wantCached {
+			t.Errorf("%q indexed by ID = %v, want %v", alias, indexed, wantCached)
+		}
+	}
+
+	before := fake.findCount()
+	mustFind(aliases[1])
+	if fake.findCount() != before+1 {
+		t.Error("evicted URL was not loaded from the repository again")
+	}
+}
+
+func TestCachedUpdateInvalidates(t *testing.T) {
+	r, _ := newTestCache(t, 64)
+	url := &domain.URL{Alias: "upd", OriginalURL: "https://example.com/old"}
+	if err := r.Create(url); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := r.FindByAlias(0, "upd", false); err != nil {
+		t.Fatalf("FindByAlias() error = %v", err)
+	}
+
+	url.OriginalURL = "https://example.com/new"
+	if err := r.Update(url); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := r.FindByAlias(0, "upd", false)
+	if err != nil {
+		t.Fatalf("FindByAlias() error = %v", err)
+	}
+	if got.OriginalURL != "https://example.com/new" {
+		t.Errorf("FindByAlias() after Update = %q, want the new destination", got.OriginalURL)
+	}
+}
+
+func TestCachedVersionGuard(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(r *cachedURLRepository, url *domain.URL) error
+	}{
+		{
+			name: "update",
+			mutate: func(r *cachedURLRepository, url *domain.URL) error {
+				changed := cloneURL(url)
+				changed.OriginalURL = "https://example.com/changed"
+				return r.Update(changed)
+			},
+		},
+		{
+			name: "soft delete of an uncached URL",
+			mutate: func(r *cachedURLRepository, url *domain.URL) error {
+				return r.SoftDelete(url.ID)
+			},
+		},
+		{
+			name: "purge",
+			mutate: func(r *cachedURLRepository, url *domain.URL) error {
+				return r.Purge(url.DomainID, url.Alias)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, fake := newTestCache(t, 64)
+			url := seed(t, fake, 0, "race")
+
+			// The first lookup reads the row, then the URL changes before the
+			// lookup caches it
+			fake.onFind = func() {
+				fake.onFind = nil
+				if err := tt.mutate(r, url); err != nil {
+					t.Errorf("mutation error = %v", err)
+				}
+			}
+			if _, err := r.FindByAlias(0, "race", true); err != nil {
+				t.Fatalf("racing FindByAlias() error = %v", err)
+			}
+
+			key := urlCacheKey(0, "race")
+			if _, found, _ := r.shard(key).get(key, time.Now()); found {
+				t.Error("row read before the mutation was cached")
+			}
+			before := fake.findCount()
+			_, _ = r.FindByAlias(0, "race", true)
+			if fake.findCount() != before+1 {
+				t.Error("lookup after the mutation did not reach the repository")
+			}
+		})
+	}
+}
+
+func TestCachedSoftDeleteByID(t *testing.T) {
+	r, fake := newTestCache(t, 64)
+	url := seed(t, fake, 3, "gone")
+
+	if _, err := r.FindByAlias(3, "gone", false); err != nil {
+		t.Fatalf("FindByAlias() error = %v", err)
+	}
+	if err := r.SoftDelete(url.ID); err != nil {
+		t.Fatalf("SoftDelete() error = %v", err)
+	}
+	if _, indexed := r.keys.Load(url.ID); indexed {
+		t.Error("soft-deleted URL is still indexed by ID")
+	}
+
+	if _, err := r.FindByAlias(3, "gone", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByAlias() after SoftDelete error = %v, want ErrNotFound", err)
+	}
+	deleted, err := r.FindByAlias(3, "gone", true)
+	if err != nil {
+		t.Fatalf("FindByAlias(includeDeleted) error = %v", err)
+	}
+	if deleted.DeletedAt == nil {
+		t.Error("soft-deleted URL has no DeletedAt")
+	}
+}
+
+func TestCachedForgetWorkspace(t *testing.T) {
+	r, fake := newTestCache(t, 64)
+	shared := &domain.URL{Alias: "team", OriginalURL: "https://example.com/team", WorkspaceID: 7}
+	if err := fake.Create(shared); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	seed(t, fake, 0, "solo")
+
+	for _, alias := range []string{"team", "solo"} {
+		if _, err := r.FindByAlias(0, alias, true); err != nil {
+			t.Fatalf("FindByAlias(%q) error = %v", alias, err)
+		}
+	}
+
+	// Deleting the workspace detaches its URLs in the database only
+	fake.mu.Lock()
+	fake.urls[urlCacheKey(0, "team")].WorkspaceID = 0
+	fake.mu.Unlock()
+	r.ForgetWorkspace(7)
+
+	finds := fake.findCount()
+	got, err := r.FindByAlias(0, "team", true)
+	if err != nil {
+		t.Fatalf("FindByAlias() error = %v", err)
+	}
+	if got.WorkspaceID != 0 {
+		t.Errorf("FindByAlias() after ForgetWorkspace has workspace %d, want 0", got.WorkspaceID)
+	}
+	if _, err := r.FindByAlias(0, "solo", true); err != nil {
+		t.Fatalf("FindByAlias() error = %v", err)
+	}
+	if got := fake.findCount() - finds; got != 1 {
+		t.Errorf("%d lookups reached the repository, want 1 for the forgotten URL only", got)
+	}
+}
+
+func TestCachedCloneIsolation(t *testing.T) {
+	r, fake := newTestCache(t, 64)
+	maxClicks := int64(10)
+	expiresAt := time.Now().Add(time.Hour)
+	url := &domain.URL{
+		Alias:       "iso",
+		OriginalURL: "https://example.com/iso",
+		MaxClicks:   &maxClicks,
+		ExpiresAt:   &expiresAt,
+		GeoRules:    []domain.GeoRule{{Countries: []string{"DE"}, URL: "https://example.de"}},
+		DeviceRules: []domain.DeviceRule{{Platform: "ios", URL: "https://example.com/ios"}},
+		Variants:    []domain.Variant{{Name: "A", URL: "https://example.com/a", Weight: 1}},
+	}
+	if err := fake.Create(url); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	first, err := r.FindByAlias(0, "iso", false)
+	if err != nil {
+		t.Fatalf("FindByAlias() error = %v", err)
+	}
+	want := cloneURL(first)
+
+	*first.MaxClicks = 1
+	*first.ExpiresAt = time.Time{}
+	first.GeoRules[0].Countries[0] = "FR"
+	first.GeoRules[0].URL = "https://example.fr"
+	first.DeviceRules[0].URL = "https://example.com/android"
+	first.Variants[0].Weight = 99
+	first.OriginalURL = "https://example.com/changed"
+
+	second, err := r.FindByAlias(0, "iso", false)
+	if err != nil {
+		t.Fatalf("FindByAlias() error = %v", err)
+	}
+	if !reflect.DeepEqual(second, want) {
+		t.Errorf("changes to a returned URL leaked into the cache:\n got  %+v\n want %+v", second, want)
+	}
+	if fake.findCount() != 1 {
+		t.Error("second lookup was not served from the cache")
+	}
+}
@@ -0,0 +1,485 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/database"
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/hll"
+
+	"github.com/lib/pq"
+)
+
+// ClickRepository defines the interface for click event data access
+type ClickRepository interface {
+	InsertBatch(events []*domain.ClickEvent) error
+	CountByInterval(urlID int64, q *domain.TimeSeriesQuery) ([]domain.TimeSeriesPoint, error)
+	CountByDimension(urlID int64, dimension string, includeBots bool, limit, offset int) ([]domain.BreakdownItem, int64, error)
+	FindDailyVisitorSketches(urlID int64, firstDay, lastDay time.Time) ([]domain.DailyVisitorSketch, error)
+}
+
+// breakdownColumns maps each breakdown dimension to its click_events column.
+// Only these columns may be interpolated into breakdown queries.
+var breakdownColumns = map[string]string{
+	domain.BreakdownReferrerHost: "referrer_host",
+	domain.BreakdownUTMSource:    "utm_source",
+	domain.BreakdownUTMMedium:    "utm_medium",
+	domain.BreakdownUTMCampaign:  "utm_campaign",
+	domain.BreakdownDevice:       "device_class",
+	domain.BreakdownOS:           "os_family",
+	domain.BreakdownBrowser:      "browser_family",
+	domain.BreakdownCountry:      "country",
+	domain.BreakdownRegion:       "region",
+	domain.BreakdownCity:         "city",
+	domain.BreakdownRule:         "matched_rule",
+	domain.BreakdownVariant:      "variant",
+}
+
+type clickRepository struct {
+	db *database.DB
+}
+
+// NewClickRepository creates a new click event repository
+func NewClickRepository(db *database.DB) ClickRepository {
+	return &clickRepository{db: db}
+}
+
+// InsertBatch stores a batch of click events, adds them to the click counters
+// of their URLs and merges their visitors into the unique visitor sketches, in
+// a single transaction. click_count and bot_click_count are only written here,
+// so they always match the stored events. Events of URLs that were purged
+// while the events were queued are skipped.
+func (r *clickRepository) InsertBatch(events []*domain.ClickEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the URLs of the batch in ID order, so concurrent writers cannot
+	// deadlock and the URLs cannot be purged before the events are stored
+	events, err = lockClickURLs(tx, events)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("click_events",
+		"url_id", "alias", "clicked_at", "referrer", "user_agent", "ip_hash", "accept_language",
+		"referrer_host", "utm_source", "utm_medium", "utm_campaign",
+		"device_class", "os_family", "browser_family",
+		"country", "region", "city", "is_bot", "matched_rule", "variant",
+	))
+	if err != nil {
+		return fmt.Errorf("failed to prepare click event copy: %w", err)
+	}
+
+	for _, event := range events {
+		_, err := stmt.Exec(
+			event.URLID,
+			event.Alias,
+			event.ClickedAt,
+			nullString(event.Referrer),
+			nullString(event.UserAgent),
+			nullString(event.IPHash),
+			nullString(event.AcceptLanguage),
+			nullString(event.ReferrerHost),
+			nullString(event.UTMSource),
+			nullString(event.UTMMedium),
+			nullString(event.UTMCampaign),
+			nullString(event.DeviceClass),
+			nullString(event.OSFamily),
+			nullString(event.BrowserFamily),
+			nullString(event.Country),
+			nullString(event.Region),
+			nullString(event.City),
+			event.IsBot,
+			nullString(event.MatchedRule),
+			nullString(event.Variant),
+		)
+		if err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy click event: %w", err)
+		}
+	}
+
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to flush click events: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to close click event copy: %w", err)
+	}
+
+	if err := addClickCounts(tx, events); err != nil {
+		return err
+	}
+	if err := mergeVisitorSketches(tx, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit click events: %w", err)
+	}
+
+	return nil
+}
+
+// lockClickURLs locks the rows of the URLs of a batch in ID order and returns
+// the events whose URL still exists
+func lockClickURLs(tx *sql.Tx, events []*domain.ClickEvent) ([]*domain.ClickEvent, error) {
+	seen := make(map[int64]bool)
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		if !seen[event.URLID] {
+			seen[event.URLID] = true
+			ids = append(ids, event.URLID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows, err := tx.Query(`SELECT id FROM urls WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock click counters: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[int64]bool, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan URL ID: %w", err)
+		}
+		existing[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lock click counters: %w", err)
+	}
+
+	if len(existing) == len(ids) {
+		return events, nil
+	}
+	kept := make([]*domain.ClickEvent, 0, len(events))
+	for _, event := range events {
+		if existing[event.URLID] {
+			kept = append(kept, event)
+		}
+	}
+	return kept, nil
+}
+
+// addClickCounts adds the human and bot clicks of a batch to the click_count
+// and bot_click_count of their URLs, one row update per URL. The URL rows
+// must already be locked by lockClickURLs.
+func addClickCounts(tx *sql.Tx, events []*domain.ClickEvent) error {
+	type counts struct{ clicks, botClicks int64 }
+	byURL := make(map[int64]*counts)
+	for _, event := range events {
+		c := byURL[event.URLID]
+		if c == nil {
+			c = &counts{}
+			byURL[event.URLID] = c
+		}
+		if event.IsBot {
+			c.botClicks++
+		} else {
+			c.clicks++
+		}
+	}
+
+	ids := make([]int64, 0, len(byURL))
+	for id := range byURL {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	clicks := make([]int64, len(ids))
+	botClicks := make([]int64, len(ids))
+	for i, id := range ids {
+		clicks[i] = byURL[id].clicks
+		botClicks[i] = byURL[id].botClicks
+	}
+
+	query := `
+		UPDATE urls
+		SET click_count = urls.click_count + d.clicks,
+		    bot_click_count = urls.bot_click_count + d.bot_clicks
+		FROM unnest($1::bigint[], $2::bigint[], $3::bigint[]) AS d(id, clicks, bot_clicks)
+		WHERE urls.id = d.id
+	`
+	if _, err := tx.Exec(query, pq.Array(ids), pq.Array(clicks), pq.Array(botClicks)); err != nil {
+		return fmt.Errorf("failed to add click counts: %w", err)
+	}
+
+	return nil
+}
+
+// mergeVisitorSketches adds the visitors of human clicks to the lifetime and
+// daily HyperLogLog sketches of their URLs and refreshes unique_visitors. The
+// URL rows are locked first, so concurrent writers cannot overwrite each
+// other's sketches.
+func mergeVisitorSketches(tx *sql.Tx, events []*domain.ClickEvent) error {
+	type dayKey struct {
+		urlID int64
+		day   string
+	}
+	lifetime := make(map[int64]*hll.Sketch)
+	daily := make(map[dayKey]*hll.Sketch)
+
+	for _, event := range events {
+		if event.IsBot || event.VisitorHash == 0 {
+			continue
+		}
+
+		if lifetime[event.URLID] == nil {
+			lifetime[event.URLID] = hll.New()
+		}
+		lifetime[event.URLID].Add(event.VisitorHash)
+
+		key := dayKey{urlID: event.URLID, day: event.ClickedAt.UTC().Format("2006-01-02")}
+		if daily[key] == nil {
+			daily[key] = hll.New()
+		}
+		daily[key].Add(event.VisitorHash)
+	}
+
+	if len(lifetime) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(lifetime))
+	for id := range lifetime {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows, err := tx.Query(`SELECT id, '', visitor_sketch FROM urls WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to lock visitor sketches: %w", err)
+	}
+	if err := mergeSketchRows(rows, func(id int64, _ string) *hll.Sketch { return lifetime[id] }); err != nil {
+		return err
+	}
+
+	sketches := make([][]byte, len(ids))
+	visitors := make([]int64, len(ids))
+	for i, id := range ids {
+		sketches[i], _ = lifetime[id].MarshalBinary()
+		visitors[i] = lifetime[id].Estimate()
+	}
+
+	query := `
+		UPDATE urls
+		SET visitor_sketch = d.sketch, unique_visitors = d.visitors
+		FROM unnest($1::bigint[], $2::bytea[], $3::bigint[]) AS d(id, sketch, visitors)
+		WHERE urls.id = d.id
+	`
+	if _, err := tx.Exec(query, pq.Array(ids), pq.Array(sketches), pq.Array(visitors)); err != nil {
+		return fmt.Errorf("failed to update visitor sketches: %w", err)
+	}
+
+	urlIDs := make([]int64, 0, len(daily))
+	days := make([]string, 0, len(daily))
+	for key := range daily {
+		urlIDs = append(urlIDs, key.urlID)
+		days = append(days, key.day)
+	}
+
+	rows, err = tx.Query(`
+		SELECT url_id, to_char(day, 'YYYY-MM-DD'), sketch
+		FROM url_daily_visitors
+		WHERE (url_id, day) IN (SELECT * FROM unnest($1::bigint[], $2::date[]))
+	`, pq.Array(urlIDs), pq.Array(days))
+	if err != nil {
+		return fmt.Errorf("failed to query daily visitor sketches: %w", err)
+	}
+	if err := mergeSketchRows(rows, func(id int64, day string) *hll.Sketch { return daily[dayKey{id, day}] }); err != nil {
+		return err
+	}
+
+	sketches = make([][]byte, len(urlIDs))
+	for i := range urlIDs {
+		sketches[i], _ = daily[dayKey{urlIDs[i], days[i]}].MarshalBinary()
+	}
+
+	query = `
+		INSERT INTO url_daily_visitors (url_id, day, sketch)
+		SELECT * FROM unnest($1::bigint[], $2::date[], $3::bytea[])
+		ON CONFLICT (url_id, day) DO UPDATE SET sketch = EXCLUDED.sketch
+	`
+	if _, err := tx.Exec(query, pq.Array(urlIDs), pq.Array(days), pq.Array(sketches)); err != nil {
+		return fmt.Errorf("failed to update daily visitor sketches: %w", err)
+	}
+
+	return nil
+}
+
+// mergeSketchRows merges the stored sketch of each (id, day, sketch) row into
+// the in-memory sketch returned by target
+func mergeSketchRows(rows *sql.Rows, target func(id int64, day string) *hll.Sketch) error {
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id   int64
+			day  string
+			data []byte
+		)
+		if err := rows.Scan(&id, &day, &data); err != nil {
+			return fmt.Errorf("failed to scan visitor sketch: %w", err)
+		}
+
+		stored, err := hll.Decode(data)
+		if err != nil {
+			return fmt.Errorf("failed to decode visitor sketch: %w", err)
+		}
+		target(id, day).Merge(stored)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	return nil
+}
+
+// CountByInterval counts the clicks on a URL in [q.From, q.To), bucketed by
+// q.Interval in the q.Location time zone. Buckets without clicks are included
+// with a zero count. Bot clicks are only counted when q.IncludeBots is set.
+func (r *clickRepository) CountByInterval(urlID int64, q *domain.TimeSeriesQuery) ([]domain.TimeSeriesPoint, error) {
+	query := `
+		WITH counts AS (
+			SELECT date_trunc($4::text, clicked_at AT TIME ZONE $5::text) AS bucket,
+			       COUNT(*) AS clicks
+			FROM click_events
+			WHERE url_id = $1
+			  AND clicked_at >= $2::timestamptz
+			  AND clicked_at < $3::timestamptz
+			  AND (NOT is_bot OR $6)
+			GROUP BY 1
+		)
+		SELECT s.bucket AT TIME ZONE $5::text, COALESCE(c.clicks, 0)
+		FROM generate_series(
+			date_trunc($4::text, $2::timestamptz AT TIME ZONE $5::text),
+			date_trunc($4::text, ($3::timestamptz - INTERVAL '1 microsecond') AT TIME ZONE $5::text),
+			('1 ' || $4::text)::interval
+		) AS s(bucket)
+		LEFT JOIN counts c ON c.bucket = s.bucket
+		ORDER BY s.bucket
+	`
+
+	rows, err := r.db.Query(query, urlID, q.From, q.To, q.Interval, q.Location.String(), q.IncludeBots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query click time series: %w", err)
+	}
+	defer rows.Close()
+
+	points := []domain.TimeSeriesPoint{}
+	for rows.Next() {
+		var (
+			bucket time.Time
+			clicks int64
+		)
+		if err := rows.Scan(&bucket, &clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan time series point: %w", err)
+		}
+		points = append(points, domain.TimeSeriesPoint{
+			Time:   bucket.In(q.Location),
+			Clicks: clicks,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return points, nil
+}
+
+// CountByDimension counts the clicks on a URL grouped by the values of a
+// breakdown dimension, most clicked first. It also returns the total number of
+// distinct values, for pagination. Bot clicks are only counted when
+// includeBots is set.
+func (r *clickRepository) CountByDimension(urlID int64, dimension string, includeBots bool, limit, offset int) ([]domain.BreakdownItem, int64, error) {
+	column, ok := breakdownColumns[dimension]
+	if !ok {
+		return nil, 0, domain.ErrInvalidBreakdown
+	}
+
+	query := `
+		SELECT value, clicks, COUNT(*) OVER () AS total
+		FROM (
+			SELECT COALESCE(` + column + `, $2) AS value, COUNT(*) AS clicks
+			FROM click_events
+			WHERE url_id = $1 AND (NOT is_bot OR $3)
+			GROUP BY 1
+		) AS g
+		ORDER BY clicks DESC, value
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := r.db.Query(query, urlID, domain.BreakdownNone, includeBots, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query click breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	items := []domain.BreakdownItem{}
+	var total int64
+	for rows.Next() {
+		var item domain.BreakdownItem
+		if err := rows.Scan(&item.Value, &item.Clicks, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan breakdown item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// FindDailyVisitorSketches returns the visitor sketch of a URL for every UTC
+// day from firstDay to lastDay inclusive, with an empty sketch for days
+// without visitors
+func (r *clickRepository) FindDailyVisitorSketches(urlID int64, firstDay, lastDay time.Time) ([]domain.DailyVisitorSketch, error) {
+	query := `
+		SELECT s.day, v.sketch
+		FROM generate_series($2::date, $3::date, INTERVAL '1 day') AS s(day)
+		LEFT JOIN url_daily_visitors v ON v.url_id = $1 AND v.day = s.day::date
+		ORDER BY s.day
+	`
+
+	rows, err := r.db.Query(query, urlID, firstDay.Format("2006-01-02"), lastDay.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily visitor sketches: %w", err)
+	}
+	defer rows.Close()
+
+	sketches := []domain.DailyVisitorSketch{}
+	for rows.Next() {
+		var sketch domain.DailyVisitorSketch
+		if err := rows.Scan(&sketch.Day, &sketch.Sketch); err != nil {
+			return nil, fmt.Errorf("failed to scan daily visitor sketch: %w", err)
+		}
+		sketch.Day = sketch.Day.UTC()
+		sketches = append(sketches, sketch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return sketches, nil
+}
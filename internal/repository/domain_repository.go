@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Faleeeee/URL_Shortener/internal/database"
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrDuplicateDomain = errors.New("domain already registered")
+	ErrDomainVerified  = errors.New("domain already verified by another user")
+	ErrDomainHasURLs   = errors.New("domain still has URLs")
+)
+
+// DomainRepository handles custom domain data access
+type DomainRepository struct {
+	db *database.DB
+}
+
+// NewDomainRepository creates a new custom domain repository
+func NewDomainRepository(db *database.DB) *DomainRepository {
+	return &DomainRepository{db: db}
+}
+
+// domainColumns is the column list scanned by scanDomain
+const domainColumns = `id, user_id, hostname, verification_token, verified_at, created_at, updated_at`
+
+// scanDomain scans a row selected with domainColumns into a custom domain
+func scanDomain(row rowScanner) (*domain.CustomDomain, error) {
+	d := &domain.CustomDomain{}
+	var verifiedAt sql.NullTime
+
+	err := row.Scan(
+		&d.ID,
+		&d.UserID,
+		&d.Hostname,
+		&d.VerificationToken,
+		&verifiedAt,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if verifiedAt.Valid {
+		d.VerifiedAt = &verifiedAt.Time
+	}
+
+	return d, nil
+}
+
+// Create registers an unverified custom domain for a user
+func (r *DomainRepository) Create(d *domain.CustomDomain) error {
+	query := `
+		INSERT INTO domains (user_id, hostname, verification_token, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, d.UserID, d.Hostname, d.VerificationToken).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateDomain
+		}
+		return fmt.Errorf("failed to create domain: %w", err)
+	}
+
+	return nil
+}
+
+// FindByUserAndHostname retrieves a user's domain by hostname, verified or not
+func (r *DomainRepository) FindByUserAndHostname(userID int64, hostname string) (*domain.CustomDomain, error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE user_id = $1 AND hostname = $2
+	`
+
+	d, err := scanDomain(r.db.QueryRow(query, userID, hostname))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find domain: %w", err)
+	}
+
+	return d, nil
+}
+
+// FindVerifiedByHostname retrieves the verified domain with a hostname
+func (r *DomainRepository) FindVerifiedByHostname(hostname string) (*domain.CustomDomain, error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE hostname = $1 AND verified_at IS NOT NULL
+	`
+
+	d, err := scanDomain(r.db.QueryRow(query, hostname))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find domain: %w", err)
+	}
+
+	return d, nil
+}
+
+// FindVerifiedHostnames retrieves the hostnames of all verified domains
+func (r *DomainRepository) FindVerifiedHostnames() ([]string, error) {
+	rows, err := r.db.Query(`SELECT hostname FROM domains WHERE verified_at IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query domains: %w", err)
+	}
+	defer rows.Close()
+
+	hostnames := []string{}
+	for rows.Next() {
+		var hostname string
+		if err := rows.Scan(&hostname); err != nil {
+			return nil, fmt.Errorf("failed to scan domain: %w", err)
+		}
+		hostnames = append(hostnames, hostname)
+	}
+
+	return hostnames, rows.Err()
+}
+
+// FindByUserID retrieves all domains of a user, ordered by hostname
+func (r *DomainRepository) FindByUserID(userID int64) ([]*domain.CustomDomain, error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE user_id = $1
+		ORDER BY hostname
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := []*domain.CustomDomain{}
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan domain: %w", err)
+		}
+		domains = append(domains, d)
+	}
+
+	return domains, rows.Err()
+}
+
+// MarkVerified records that the owner of a domain proved control of it
+func (r *DomainRepository) MarkVerified(d *domain.CustomDomain) error {
+	query := `
+		UPDATE domains
+		SET verified_at = NOW(),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING verified_at, updated_at
+	`
+
+	var verifiedAt sql.NullTime
+	err := r.db.QueryRow(query, d.ID).Scan(&verifiedAt, &d.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrDomainVerified
+		}
+		return fmt.Errorf("failed to verify domain: %w", err)
+	}
+
+	d.VerifiedAt = &verifiedAt.Time
+	return nil
+}
+
+// Delete removes a domain. ErrDomainHasURLs is returned while any URL,
+// soft-deleted or not, is still served from it.
+func (r *DomainRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM domains WHERE id = $1`, id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrDomainHasURLs
+		}
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint
+// violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isUniqueViolationOf reports whether err is a PostgreSQL unique constraint
+// violation of a particular constraint or unique index
+func isUniqueViolationOf(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// isForeignKeyViolation reports whether err is a PostgreSQL foreign key
+// violation, such as deleting a row that is still referenced
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -14,14 +15,151 @@ var (
 	ErrDuplicateAlias = errors.New("alias already exists")
 )
 
-// URLRepository defines the interface for URL data access
+// URLRepository defines the interface for URL data access. Aliases are unique
+// per domain; a domainID of zero stands for BASE_URL.
 type URLRepository interface {
 	Create(url *domain.URL) error
-	FindByAlias(alias string) (*domain.URL, error)
-	IncrementClickCount(alias string) error
-	FindAll(limit, offset int) ([]*domain.URL, error)
-	FindByUserID(userID int64, limit, offset int) ([]*domain.URL, error)
-	ExistsByAlias(alias string) (bool, error)
+	FindByAlias(domainID int64, alias string, includeDeleted bool) (*domain.URL, error)
+	Update(url *domain.URL) error
+	SoftDelete(id int64) error
+	Purge(domainID int64, alias string) error
+	ClaimClick(domainID int64, alias string) (bool, error)
+	FindAll(limit, offset int, includeDeleted bool) ([]*domain.URL, error)
+	FindByUserID(userID int64, limit, offset int, includeDeleted bool) ([]*domain.URL, error)
+	FindByWorkspaceID(workspaceID int64, limit, offset int, includeDeleted bool) ([]*domain.URL, error)
+	ExistsByAlias(domainID int64, alias string) (bool, error)
+	// ForgetWorkspace drops cached URLs of a workspace after the database
+	// changed them behind the repository's back, as when deleting the
+	// workspace detaches its URLs
+	ForgetWorkspace(workspaceID int64)
+}
+
+// urlColumns is the column list shared by every query that scans a full URL row
+const urlColumns = `id, alias, COALESCE(domain_id, 0), (SELECT hostname FROM domains WHERE domains.id = urls.domain_id), original_url, create_id, COALESCE(workspace_id, 0), click_count, bot_click_count, claimed_clicks, unique_visitors, created_at, updated_at, deleted_at,
+	expires_at, max_clicks, expired_redirect_url, redirect_type, password_hash,
+	title, force_preview, geo_rules, device_rules, variants, query_policy,
+	og_title, og_description, og_image`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanURL scans a row selected with urlColumns into a URL
+func scanURL(row rowScanner) (*domain.URL, error) {
+	url := &domain.URL{}
+	var (
+		hostname           sql.NullString
+		deletedAt          sql.NullTime
+		expiresAt          sql.NullTime
+		maxClicks          sql.NullInt64
+		expiredRedirectURL sql.NullString
+		passwordHash       sql.NullString
+		title              sql.NullString
+		geoRules           []byte
+		deviceRules        []byte
+		variants           []byte
+		ogTitle            sql.NullString
+		ogDescription      sql.NullString
+		ogImage            sql.NullString
+	)
+
+	err := row.Scan(
+		&url.ID,
+		&url.Alias,
+		&url.DomainID,
+		&hostname,
+		&url.OriginalURL,
+		&url.UserID,
+		&url.WorkspaceID,
+		&url.ClickCount,
+		&url.BotClickCount,
+		&url.ClaimedClicks,
+		&url.UniqueVisitors,
+		&url.CreatedAt,
+		&url.UpdatedAt,
+		&deletedAt,
+		&expiresAt,
+		&maxClicks,
+		&expiredRedirectURL,
+		&url.RedirectType,
+		&passwordHash,
+		&title,
+		&url.ForcePreview,
+		&geoRules,
+		&deviceRules,
+		&variants,
+		&url.QueryPolicy,
+		&ogTitle,
+		&ogDescription,
+		&ogImage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if deletedAt.Valid {
+		url.DeletedAt = &deletedAt.Time
+	}
+	if expiresAt.Valid {
+		url.ExpiresAt = &expiresAt.Time
+	}
+	if maxClicks.Valid {
+		url.MaxClicks = &maxClicks.Int64
+	}
+	url.Domain = hostname.String
+	url.ExpiredRedirectURL = expiredRedirectURL.String
+	url.PasswordHash = passwordHash.String
+	url.Title = title.String
+	url.OGTitle = ogTitle.String
+	url.OGDescription = ogDescription.String
+	url.OGImage = ogImage.String
+	if err := unmarshalRules(geoRules, &url.GeoRules); err != nil {
+		return nil, err
+	}
+	if err := unmarshalRules(deviceRules, &url.DeviceRules); err != nil {
+		return nil, err
+	}
+	if err := unmarshalRules(variants, &url.Variants); err != nil {
+		return nil, err
+	}
+
+	return url, nil
+}
+
+// marshalRules encodes a list of targeting rules for a JSONB column, storing
+// NULL when there are none. The JSON is passed as a string, since pq would
+// send a byte slice as bytea.
+func marshalRules[T any](rules []T) (sql.NullString, error) {
+	if len(rules) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode rules: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// unmarshalRules decodes a JSONB column of targeting rules, leaving rules
+// empty for NULL
+func unmarshalRules[T any](data []byte, rules *[]T) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, rules); err != nil {
+		return fmt.Errorf("failed to decode rules: %w", err)
+	}
+	return nil
+}
+
+// deletedFilter returns the SQL condition that hides soft-deleted rows unless
+// includeDeleted is set
+func deletedFilter(includeDeleted bool) string {
+	if includeDeleted {
+		return "TRUE"
+	}
+	return "deleted_at IS NULL"
 }
 
 type urlRepository struct {
@@ -36,23 +174,57 @@ func NewURLRepository(db *database.DB) URLRepository {
 // Create inserts a new URL into the database
 func (r *urlRepository) Create(url *domain.URL) error {
 	query := `
-		INSERT INTO urls (alias, original_url, create_id, click_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
-		RETURNING id, created_at, updated_at
+		INSERT INTO urls (alias, original_url, create_id, click_count, expires_at, max_clicks, expired_redirect_url, redirect_type, password_hash,
+		                  title, force_preview, geo_rules, device_rules, variants, query_policy,
+		                  og_title, og_description, og_image, domain_id, workspace_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7,
+		        COALESCE(NULLIF($8, 0), (SELECT default_redirect_type FROM users WHERE id = $3), $9),
+		        $10, $11, $12, $13, $14, $15, $16,
+		        $17, $18, $19, NULLIF($20, 0), NULLIF($21, 0), NOW(), NOW())
+		RETURNING id, redirect_type, created_at, updated_at
 	`
 
-	err := r.db.QueryRow(
+	geoRules, err := marshalRules(url.GeoRules)
+	if err != nil {
+		return err
+	}
+	deviceRules, err := marshalRules(url.DeviceRules)
+	if err != nil {
+		return err
+	}
+	variants, err := marshalRules(url.Variants)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(
 		query,
 		url.Alias,
 		url.OriginalURL,
 		url.UserID,
 		url.ClickCount,
-	).Scan(&url.ID, &url.CreatedAt, &url.UpdatedAt)
+		url.ExpiresAt,
+		url.MaxClicks,
+		nullString(url.ExpiredRedirectURL),
+		url.RedirectType,
+		domain.DefaultRedirectType,
+		nullString(url.PasswordHash),
+		nullString(url.Title),
+		url.ForcePreview,
+		geoRules,
+		deviceRules,
+		variants,
+		url.QueryPolicy,
+		nullString(url.OGTitle),
+		nullString(url.OGDescription),
+		nullString(url.OGImage),
+		url.DomainID,
+		url.WorkspaceID,
+	).Scan(&url.ID, &url.RedirectType, &url.CreatedAt, &url.UpdatedAt)
 
 	if err != nil {
 		// Check for unique constraint violation
-		if err.Error() == "pq: duplicate key value violates unique constraint \"urls_alias_key\"" ||
-			err.Error() == "pq: duplicate key value violates unique constraint \"idx_alias\"" {
+		if isUniqueViolationOf(err, "idx_urls_domain_alias") {
 			return ErrDuplicateAlias
 		}
 		return fmt.Errorf("failed to create URL: %w", err)
@@ -61,25 +233,15 @@ func (r *urlRepository) Create(url *domain.URL) error {
 	return nil
 }
 
-// FindByAlias retrieves a URL by its alias
-func (r *urlRepository) FindByAlias(alias string) (*domain.URL, error) {
+// FindByAlias retrieves a URL by its domain and alias. Soft-deleted URLs are
+// only returned when includeDeleted is set.
+func (r *urlRepository) FindByAlias(domainID int64, alias string, includeDeleted bool) (*domain.URL, error) {
 	query := `
-		SELECT id, alias, original_url, create_id, click_count, created_at, updated_at
+		SELECT ` + urlColumns + `
 		FROM urls
-		WHERE alias = $1
-	`
-
-	url := &domain.URL{}
-	err := r.db.QueryRow(query, alias).Scan(
-		&url.ID,
-		&url.Alias,
-		&url.OriginalURL,
-		&url.UserID,
-		&url.ClickCount,
-		&url.CreatedAt,
-		&url.UpdatedAt,
-	)
+		WHERE COALESCE(domain_id, 0) = $1 AND alias = $2 AND ` + deletedFilter(includeDeleted)
 
+	url, err := scanURL(r.db.QueryRow(query, domainID, alias))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -90,18 +252,108 @@ func (r *urlRepository) FindByAlias(alias string) (*domain.URL, error) {
 	return url, nil
 }
 
-// IncrementClickCount atomically increments the click counter for a URL
-func (r *urlRepository) IncrementClickCount(alias string) error {
+// Update persists the mutable fields of an existing URL
+func (r *urlRepository) Update(url *domain.URL) error {
 	query := `
 		UPDATE urls
-		SET click_count = click_count + 1,
+		SET original_url = $2,
+		    expires_at = $3,
+		    max_clicks = $4,
+		    expired_redirect_url = $5,
+		    redirect_type = $6,
+		    password_hash = $7,
+		    title = $8,
+		    force_preview = $9,
+		    geo_rules = $10,
+		    device_rules = $11,
+		    variants = $12,
+		    query_policy = $13,
+		    og_title = $14,
+		    og_description = $15,
+		    og_image = $16,
+		    workspace_id = NULLIF($17, 0),
 		    updated_at = NOW()
-		WHERE alias = $1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
 	`
 
-	result, err := r.db.Exec(query, alias)
+	geoRules, err := marshalRules(url.GeoRules)
+	if err != nil {
+		return err
+	}
+	deviceRules, err := marshalRules(url.DeviceRules)
 	if err != nil {
-		return fmt.Errorf("failed to increment click count: %w", err)
+		return err
+	}
+	variants, err := marshalRules(url.Variants)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(
+		query,
+		url.ID,
+		url.OriginalURL,
+		url.ExpiresAt,
+		url.MaxClicks,
+		nullString(url.ExpiredRedirectURL),
+		url.RedirectType,
+		nullString(url.PasswordHash),
+		nullString(url.Title),
+		url.ForcePreview,
+		geoRules,
+		deviceRules,
+		variants,
+		url.QueryPolicy,
+		nullString(url.OGTitle),
+		nullString(url.OGDescription),
+		nullString(url.OGImage),
+		url.WorkspaceID,
+	).Scan(&url.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	return nil
+}
+
+// SoftDelete marks a URL as deleted while keeping its row, so the alias stays
+// reserved and redirects can report it as gone
+func (r *urlRepository) SoftDelete(id int64) error {
+	query := `
+		UPDATE urls
+		SET deleted_at = NOW(),
+		    updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete URL: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Purge permanently removes a URL, whether or not it was soft-deleted
+func (r *urlRepository) Purge(domainID int64, alias string) error {
+	query := `DELETE FROM urls WHERE COALESCE(domain_id, 0) = $1 AND alias = $2`
+
+	result, err := r.db.Exec(query, domainID, alias)
+	if err != nil {
+		return fmt.Errorf("failed to purge URL: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -116,11 +368,45 @@ func (r *urlRepository) IncrementClickCount(alias string) error {
 	return nil
 }
 
-// FindAll retrieves all URLs with pagination
-func (r *urlRepository) FindAll(limit, offset int) ([]*domain.URL, error) {
+// ClaimClick counts a click against the click cap only if the URL is still
+// active, i.e. not deleted, not past its expiry time and below its click cap.
+// The check and the increment happen in a single statement, so concurrent
+// clicks can never push the count past max_clicks. It reports whether the
+// click was counted.
+//
+// Claims go to claimed_clicks, which starts from click_count, so click_count
+// itself is only written together with the click events it counts.
+func (r *urlRepository) ClaimClick(domainID int64, alias string) (bool, error) {
+	query := `
+		UPDATE urls
+		SET claimed_clicks = GREATEST(claimed_clicks, click_count) + 1,
+		    updated_at = NOW()
+		WHERE COALESCE(domain_id, 0) = $1 AND alias = $2
+		  AND deleted_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		  AND (max_clicks IS NULL OR GREATEST(claimed_clicks, click_count) < max_clicks)
+	`
+
+	result, err := r.db.Exec(query, domainID, alias)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim click: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// FindAll retrieves all URLs with pagination. Soft-deleted URLs are only
+// returned when includeDeleted is set.
+func (r *urlRepository) FindAll(limit, offset int, includeDeleted bool) ([]*domain.URL, error) {
 	query := `
-		SELECT id, alias, original_url, create_id, click_count, created_at, updated_at
+		SELECT ` + urlColumns + `
 		FROM urls
+		WHERE ` + deletedFilter(includeDeleted) + `
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -131,81 +417,85 @@ func (r *urlRepository) FindAll(limit, offset int) ([]*domain.URL, error) {
 	}
 	defer rows.Close()
 
-	var urls []*domain.URL
-	for rows.Next() {
-		url := &domain.URL{}
-		err := rows.Scan(
-			&url.ID,
-			&url.Alias,
-			&url.OriginalURL,
-			&url.UserID,
-			&url.ClickCount,
-			&url.CreatedAt,
-			&url.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan URL: %w", err)
-		}
-		urls = append(urls, url)
-	}
+	return scanURLs(rows)
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
+// FindByUserID retrieves the personal URLs created by a specific user, outside
+// any workspace, with pagination.
+// Soft-deleted URLs are only returned when includeDeleted is set.
+func (r *urlRepository) FindByUserID(userID int64, limit, offset int, includeDeleted bool) ([]*domain.URL, error) {
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE create_id = $1 AND workspace_id IS NULL AND ` + deletedFilter(includeDeleted) + `
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query URLs by user ID: %w", err)
 	}
+	defer rows.Close()
 
-	return urls, nil
+	return scanURLs(rows)
 }
 
-// FindByUserID retrieves all URLs created by a specific user with pagination
-func (r *urlRepository) FindByUserID(userID int64, limit, offset int) ([]*domain.URL, error) {
+// FindByWorkspaceID retrieves the URLs of a workspace with pagination.
+// Soft-deleted URLs are only returned when includeDeleted is set.
+func (r *urlRepository) FindByWorkspaceID(workspaceID int64, limit, offset int, includeDeleted bool) ([]*domain.URL, error) {
 	query := `
-		SELECT id, alias, original_url, create_id, click_count, created_at, updated_at
+		SELECT ` + urlColumns + `
 		FROM urls
-		WHERE create_id = $1
+		WHERE workspace_id = $1 AND ` + deletedFilter(includeDeleted) + `
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	rows, err := r.db.Query(query, workspaceID, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query URLs by user ID: %w", err)
+		return nil, fmt.Errorf("failed to query URLs by workspace ID: %w", err)
 	}
 	defer rows.Close()
 
+	return scanURLs(rows)
+}
+
+// scanURLs reads every remaining row selected with urlColumns
+func scanURLs(rows *sql.Rows) ([]*domain.URL, error) {
 	var urls []*domain.URL
 	for rows.Next() {
-		url := &domain.URL{}
-		err := rows.Scan(
-			&url.ID,
-			&url.Alias,
-			&url.OriginalURL,
-			&url.UserID,
-			&url.ClickCount,
-			&url.CreatedAt,
-			&url.UpdatedAt,
-		)
+		url, err := scanURL(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan URL: %w", err)
 		}
 		urls = append(urls, url)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
 	return urls, nil
 }
 
-// ExistsByAlias checks if an alias already exists
-func (r *urlRepository) ExistsByAlias(alias string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM urls WHERE alias = $1)`
+// ForgetWorkspace does nothing, as nothing is cached
+func (r *urlRepository) ForgetWorkspace(workspaceID int64) {}
+
+// ExistsByAlias checks if an alias already exists on a domain
+func (r *urlRepository) ExistsByAlias(domainID int64, alias string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM urls WHERE COALESCE(domain_id, 0) = $1 AND alias = $2)`
 
 	var exists bool
-	err := r.db.QueryRow(query, alias).Scan(&exists)
+	err := r.db.QueryRow(query, domainID, alias).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check alias existence: %w", err)
 	}
 
 	return exists, nil
 }
+
+// nullString maps an empty string to SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -22,25 +22,28 @@ func NewUserRepository(db *database.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// CreateUser creates a new user in the database
-func (r *UserRepository) CreateUser(username, hashedPassword string) (*domain.User, error) {
+// CreateUser creates a new user with a role in the database
+func (r *UserRepository) CreateUser(username, hashedPassword, role string) (*domain.User, error) {
 	query := `
-		INSERT INTO users (username, password, created_at, updated_at)
-		VALUES ($1, $2, NOW(), NOW())
-		RETURNING id, username, created_at, updated_at
+		INSERT INTO users (username, password, role, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id, username, default_redirect_type, created_at, updated_at
 	`
 
 	user := &domain.User{
 		Password: hashedPassword,
+		Role:     role,
 	}
 
 	err := r.db.QueryRow(
 		query,
 		username,
 		hashedPassword,
+		role,
 	).Scan(
 		&user.ID,
 		&user.Username,
+		&user.DefaultRedirectType,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -59,7 +62,7 @@ func (r *UserRepository) CreateUser(username, hashedPassword string) (*domain.Us
 // GetUserByUsername retrieves a user by username
 func (r *UserRepository) GetUserByUsername(username string) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, created_at, updated_at
+		SELECT id, username, password, role, default_redirect_type, created_at, updated_at
 		FROM users
 		WHERE username = $1
 	`
@@ -69,6 +72,8 @@ func (r *UserRepository) GetUserByUsername(username string) (*domain.User, error
 		&user.ID,
 		&user.Username,
 		&user.Password,
+		&user.Role,
+		&user.DefaultRedirectType,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -86,7 +91,7 @@ func (r *UserRepository) GetUserByUsername(username string) (*domain.User, error
 // GetUserByID retrieves a user by ID
 func (r *UserRepository) GetUserByID(id int64) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, created_at, updated_at
+		SELECT id, username, password, role, default_redirect_type, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -96,6 +101,8 @@ func (r *UserRepository) GetUserByID(id int64) (*domain.User, error) {
 		&user.ID,
 		&user.Username,
 		&user.Password,
+		&user.Role,
+		&user.DefaultRedirectType,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -109,3 +116,163 @@ func (r *UserRepository) GetUserByID(id int64) (*domain.User, error) {
 
 	return user, nil
 }
+
+// UpdateDefaultRedirectType changes the redirect type applied to new URLs of
+// a user
+func (r *UserRepository) UpdateDefaultRedirectType(id int64, redirectType int) error {
+	query := `
+		UPDATE users
+		SET default_redirect_type = $2,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, id, redirectType)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// FindAll retrieves users ordered by ID, with pagination
+func (r *UserRepository) FindAll(limit, offset int) ([]*domain.User, error) {
+	query := `
+		SELECT id, username, role, default_redirect_type, created_at, updated_at
+		FROM users
+		ORDER BY id
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*domain.User{}
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Role,
+			&user.DefaultRedirectType,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// UpdateRole changes the role of a user. Demoting an admin fails with
+// domain.ErrLastAdmin if no other admin would remain. The admin rows are
+// locked first, so two admins demoting each other at the same time cannot
+// both succeed.
+func (r *UserRepository) UpdateRole(id int64, role string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id, role FROM users WHERE role = 'admin' OR id = $1 ORDER BY id FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+
+	var (
+		found       bool
+		currentRole string
+		admins      int
+	)
+	for rows.Next() {
+		var (
+			rowID   int64
+			rowRole string
+		)
+		if err := rows.Scan(&rowID, &rowRole); err != nil {
+			rows.Close()
+			return err
+		}
+		if rowRole == domain.RoleAdmin {
+			admins++
+		}
+		if rowID == id {
+			found = true
+			currentRole = rowRole
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrNotFound
+	}
+	if currentRole == domain.RoleAdmin && role != domain.RoleAdmin && admins < 2 {
+		return domain.ErrLastAdmin
+	}
+
+	query := `
+		UPDATE users
+		SET role = $2,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := tx.Exec(query, id, role); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PromoteLegacyAdmin makes a user that was created before roles existed an
+// admin. It reports false when there is no such user.
+func (r *UserRepository) PromoteLegacyAdmin(username string) (bool, error) {
+	query := `
+		UPDATE users
+		SET role = 'admin',
+		    updated_at = NOW()
+		WHERE username = $1
+		  AND predates_roles
+	`
+
+	result, err := r.db.Exec(query, username)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// HasRole reports whether any user has a role
+func (r *UserRepository) HasRole(role string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE role = $1)`, role).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Faleeeee/URL_Shortener/internal/database"
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+)
+
+// UTMPresetRepository handles UTM preset data access
+type UTMPresetRepository struct {
+	db *database.DB
+}
+
+// NewUTMPresetRepository creates a new UTM preset repository
+func NewUTMPresetRepository(db *database.DB) *UTMPresetRepository {
+	return &UTMPresetRepository{db: db}
+}
+
+// utmPresetColumns is the column list scanned by scanUTMPreset
+const utmPresetColumns = `name, source, medium, campaign, term, content, created_at, updated_at`
+
+// scanUTMPreset scans a row selected with utmPresetColumns into a preset
+func scanUTMPreset(row rowScanner) (*domain.UTMPreset, error) {
+	preset := &domain.UTMPreset{}
+	var source, medium, campaign, term, content sql.NullString
+
+	err := row.Scan(
+		&preset.Name,
+		&source,
+		&medium,
+		&campaign,
+		&term,
+		&content,
+		&preset.CreatedAt,
+		&preset.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	preset.Source = source.String
+	preset.Medium = medium.String
+	preset.Campaign = campaign.String
+	preset.Term = term.String
+	preset.Content = content.String
+
+	return preset, nil
+}
+
+// Save creates a user's preset, or replaces the parameters of the preset with
+// the same name
+func (r *UTMPresetRepository) Save(userID int64, preset *domain.UTMPreset) error {
+	query := `
+		INSERT INTO utm_presets (user_id, name, source, medium, campaign, term, content, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (user_id, name) DO UPDATE
+		SET source = EXCLUDED.source,
+		    medium = EXCLUDED.medium,
+		    campaign = EXCLUDED.campaign,
+		    term = EXCLUDED.term,
+		    content = EXCLUDED.content,
+		    updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		userID,
+		preset.Name,
+		nullString(preset.Source),
+		nullString(preset.Medium),
+		nullString(preset.Campaign),
+		nullString(preset.Term),
+		nullString(preset.Content),
+	).Scan(&preset.CreatedAt, &preset.UpdatedAt)
+}
+
+// FindByName retrieves a user's preset by name
+func (r *UTMPresetRepository) FindByName(userID int64, name string) (*domain.UTMPreset, error) {
+	query := `
+		SELECT ` + utmPresetColumns + `
+		FROM utm_presets
+		WHERE user_id = $1 AND name = $2
+	`
+
+	preset, err := scanUTMPreset(r.db.QueryRow(query, userID, name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return preset, nil
+}
+
+// FindByUserID retrieves all presets of a user, ordered by name
+func (r *UTMPresetRepository) FindByUserID(userID int64) ([]*domain.UTMPreset, error) {
+	query := `
+		SELECT ` + utmPresetColumns + `
+		FROM utm_presets
+		WHERE user_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	presets := []*domain.UTMPreset{}
+	for rows.Next() {
+		preset, err := scanUTMPreset(rows)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, preset)
+	}
+
+	return presets, rows.Err()
+}
+
+// Delete removes a user's preset by name
+func (r *UTMPresetRepository) Delete(userID int64, name string) error {
+	result, err := r.db.Exec(`DELETE FROM utm_presets WHERE user_id = $1 AND name = $2`, userID, name)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Faleeeee/URL_Shortener/internal/database"
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+)
+
+var ErrDuplicateMember = errors.New("user is already a member")
+
+// WorkspaceRepository handles workspace and membership data access
+type WorkspaceRepository struct {
+	db *database.DB
+}
+
+// NewWorkspaceRepository creates a new workspace repository
+func NewWorkspaceRepository(db *database.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+// Create stores a new workspace with ownerID as its first owner
+func (r *WorkspaceRepository) Create(ws *domain.Workspace, ownerID int64) error {
+	query := `
+		WITH workspace AS (
+			INSERT INTO workspaces (name, created_at, updated_at)
+			VALUES ($1, NOW(), NOW())
+			RETURNING id, created_at, updated_at
+		), owner AS (
+			INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+			SELECT id, $2, $3, NOW() FROM workspace
+		)
+		SELECT id, created_at, updated_at FROM workspace
+	`
+
+	err := r.db.QueryRow(query, ws.Name, ownerID, domain.WorkspaceRoleOwner).Scan(&ws.ID, &ws.CreatedAt, &ws.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	ws.Role = domain.WorkspaceRoleOwner
+	return nil
+}
+
+// FindByUserID retrieves the workspaces a user is a member of, with the
+// user's role, ordered by name
+func (r *WorkspaceRepository) FindByUserID(userID int64) ([]*domain.Workspace, error) {
+	query := `
+		SELECT w.id, w.name, m.role, w.created_at, w.updated_at
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.name, w.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []*domain.Workspace{}
+	for rows.Next() {
+		ws := &domain.Workspace{}
+		if err := rows.Scan(&ws.ID, &ws.Name, &ws.Role, &ws.CreatedAt, &ws.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// FindRole returns the role of a user in a workspace, or ErrNotFound if the
+// user is not a member
+func (r *WorkspaceRepository) FindRole(workspaceID, userID int64) (string, error) {
+	var role string
+	err := r.db.QueryRow(
+		`SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceID, userID,
+	).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to find workspace role: %w", err)
+	}
+
+	return role, nil
+}
+
+// FindMembers retrieves the members of a workspace, ordered by username
+func (r *WorkspaceRepository) FindMembers(workspaceID int64) ([]*domain.WorkspaceMember, error) {
+	query := `
+		SELECT m.user_id, u.username, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY u.username
+	`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*domain.WorkspaceMember{}
+	for rows.Next() {
+		member := &domain.WorkspaceMember{}
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Role, &member.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// AddMember adds the user with a username to a workspace. It returns
+// ErrNotFound if there is no such user.
+func (r *WorkspaceRepository) AddMember(workspaceID int64, username, role string) (*domain.WorkspaceMember, error) {
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		SELECT $1, id, $3, NOW() FROM users WHERE username = $2
+		RETURNING user_id, created_at
+	`
+
+	member := &domain.WorkspaceMember{Username: username, Role: role}
+	err := r.db.QueryRow(query, workspaceID, username, role).Scan(&member.UserID, &member.JoinedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrDuplicateMember
+		}
+		return nil, fmt.Errorf("failed to add workspace member: %w", err)
+	}
+
+	return member, nil
+}
+
+// UpdateMemberRole changes the role of a workspace member
+func (r *WorkspaceRepository) UpdateMemberRole(workspaceID, userID int64, role string) error {
+	result, err := r.db.Exec(
+		`UPDATE workspace_members SET role = $3 WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceID, userID, role,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update workspace member: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// RemoveMember removes a user from a workspace
+func (r *WorkspaceRepository) RemoveMember(workspaceID, userID int64) error {
+	result, err := r.db.Exec(
+		`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// CountOwners returns the number of owners of a workspace
+func (r *WorkspaceRepository) CountOwners(workspaceID int64) (int64, error) {
+	var count int64
+	err := r.db.QueryRow(
+		`SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = $2`,
+		workspaceID, domain.WorkspaceRoleOwner,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count workspace owners: %w", err)
+	}
+	return count, nil
+}
+
+// CountActiveURLs returns the number of URLs of a workspace that are not
+// soft-deleted
+func (r *WorkspaceRepository) CountActiveURLs(workspaceID int64) (int64, error) {
+	var count int64
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM urls WHERE workspace_id = $1 AND deleted_at IS NULL`, workspaceID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count workspace URLs: %w", err)
+	}
+	return count, nil
+}
+
+// Delete removes a workspace along with its memberships. URLs still in it
+// become personal URLs of their creators.
+func (r *WorkspaceRepository) Delete(workspaceID int64) error {
+	result, err := r.db.Exec(`DELETE FROM workspaces WHERE id = $1`, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// expectRow returns ErrNotFound if a statement affected no rows
+func expectRow(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -1,13 +1,18 @@
 package server
 
 import (
+	"log"
 	"time"
 
+	"github.com/Faleeeee/URL_Shortener/internal/config"
 	"github.com/Faleeeee/URL_Shortener/internal/database"
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/geoip"
 	"github.com/Faleeeee/URL_Shortener/internal/handler"
 	"github.com/Faleeeee/URL_Shortener/internal/middleware"
 	"github.com/Faleeeee/URL_Shortener/internal/repository"
 	"github.com/Faleeeee/URL_Shortener/internal/service"
+	"github.com/Faleeeee/URL_Shortener/internal/useragent"
 	"github.com/Faleeeee/URL_Shortener/internal/utils"
 
 	"github.com/gin-contrib/cors"
@@ -16,46 +21,140 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func NewRouter(db *database.DB, baseURL, jwtSecret string, jwtExpiration time.Duration, base62Chars string) *gin.Engine {
+func NewRouter(cfg *config.Config, db *database.DB, clickWriter *service.ClickWriter, geoResolver *geoip.Resolver, linkGuard *service.LinkGuard, jwtExpiration, urlCacheTTL, urlCacheNegativeTTL time.Duration) *gin.Engine {
 	r := gin.Default()
 
+	// Only honor X-Forwarded-For from configured proxies, so clients cannot
+	// spoof their IP (and with it their location)
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Printf("Invalid TRUSTED_PROXIES, trusting no proxies: %v", err)
+		r.SetTrustedProxies(nil)
+	}
+
 	// CORS for Swagger
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
-	r.Use(cors.New(config))
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-API-Key"}
+	r.Use(cors.New(corsConfig))
 
 	// Initialize JWT Manager
-	jwtManager := utils.NewJWTManager(jwtSecret, jwtExpiration)
+	jwtManager := utils.NewJWTManager(cfg.JWT.Secret, jwtExpiration)
+
+	// Initialize middleware. API keys are only accepted by the routes that
+	// name the scope they need.
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db))
+	authMiddleware := middleware.AuthMiddleware(jwtManager, apiKeyService)
+	linksWriteAuth := middleware.AuthMiddleware(jwtManager, apiKeyService, domain.ScopeLinksWrite)
+	linksReadAuth := middleware.AuthMiddleware(jwtManager, apiKeyService, domain.ScopeLinksRead)
+	statsReadAuth := middleware.AuthMiddleware(jwtManager, apiKeyService, domain.ScopeStatsRead)
+
+	// Initialize custom domain lookups, shared by redirects and referrer
+	// classification
+	domainService := service.NewDomainService(repository.NewDomainRepository(db), nil, cfg.Server.BaseURL, urlCacheTTL)
 
-	// Initialize middleware
-	authMiddleware := middleware.AuthMiddleware(jwtManager)
+	// Initialize Analytics layers
+	clickRepo := repository.NewClickRepository(db)
+	analyticsService := service.NewAnalyticsService(clickRepo, clickWriter, useragent.NewParser(), geoResolver, domainService, cfg.Analytics.IPHashSalt, cfg.Server.BaseURL)
 
-	// Initialize URL layers
-	urlRepo := repository.NewURLRepository(db)
-	urlService := service.NewURLService(urlRepo, baseURL, base62Chars)
-	urlHandler := handler.NewURLHandler(urlService, baseURL)
+	// Initialize URL layers, with alias lookups cached in memory for the
+	// redirect hot path
+	urlRepo := repository.NewCachedURLRepository(
+		repository.NewURLRepository(db),
+		cfg.Cache.URLSize,
+		urlCacheTTL,
+		urlCacheNegativeTTL,
+	)
+	utmPresetRepo := repository.NewUTMPresetRepository(db)
+	workspaceService := service.NewWorkspaceService(repository.NewWorkspaceRepository(db), urlRepo)
+	urlService := service.NewURLService(urlRepo, utmPresetRepo, domainService, workspaceService, analyticsService, cfg.Server.BaseURL, cfg.Shortener.Base62Chars)
+	urlHandler := handler.NewURLHandler(urlService, linkGuard, cfg.Server.BaseURL)
+	analyticsHandler := handler.NewAnalyticsHandler(urlService, analyticsService)
+	domainHandler := handler.NewDomainHandler(domainService)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
 
-	// Initialize Auth layers
+	// Initialize Auth layers, making sure there is a first admin
 	userRepo := repository.NewUserRepository(db)
 	authService := service.NewAuthService(userRepo, jwtManager)
 	authHandler := handler.NewAuthHandler(authService)
+	if err := authService.SeedAdmins(cfg.Admin.Usernames); err != nil {
+		log.Printf("Failed to seed admin users: %v", err)
+	}
+	if err := authService.BootstrapAdmin(cfg.Admin.BootstrapUsername, cfg.Admin.BootstrapPassword); err != nil {
+		log.Printf("Failed to bootstrap admin user: %v", err)
+	}
+
+	// Initialize User layers
+	userService := service.NewUserService(userRepo, utmPresetRepo)
+	userHandler := handler.NewUserHandler(userService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	// Initialize Admin layers
+	adminHandler := handler.NewAdminHandler(clickWriter, userService)
+	requireAdmin := middleware.RequireRole(userService, domain.RoleAdmin)
 
 	// Authentication routes
 	r.POST("/auth/register", authHandler.Register)
 	r.POST("/auth/login", authHandler.Login)
 
+	// User settings and UTM preset routes (require authentication)
+	r.GET("/user/settings", authMiddleware, userHandler.GetSettings)
+	r.PATCH("/user/settings", authMiddleware, userHandler.UpdateSettings)
+	r.GET("/user/utm-presets", authMiddleware, userHandler.ListUTMPresets)
+	r.PUT("/user/utm-presets/:name", authMiddleware, userHandler.SaveUTMPreset)
+	r.DELETE("/user/utm-presets/:name", authMiddleware, userHandler.DeleteUTMPreset)
+
+	// API key routes (require signing in; API keys cannot manage keys)
+	r.POST("/user/api-keys", authMiddleware, apiKeyHandler.CreateAPIKey)
+	r.GET("/user/api-keys", authMiddleware, apiKeyHandler.ListAPIKeys)
+	r.DELETE("/user/api-keys/:id", authMiddleware, apiKeyHandler.RevokeAPIKey)
+
+	// Custom domain routes (require authentication)
+	r.POST("/domains", authMiddleware, domainHandler.RegisterDomain)
+	r.GET("/domains", authMiddleware, domainHandler.ListDomains)
+	r.POST("/domains/:hostname/verify", authMiddleware, domainHandler.VerifyDomain)
+	r.DELETE("/domains/:hostname", authMiddleware, domainHandler.DeleteDomain)
+
+	// Workspace routes (require authentication)
+	r.POST("/workspaces", authMiddleware, workspaceHandler.CreateWorkspace)
+	r.GET("/workspaces", authMiddleware, workspaceHandler.ListWorkspaces)
+	r.DELETE("/workspaces/:id", authMiddleware, workspaceHandler.DeleteWorkspace)
+	r.GET("/workspaces/:id/members", authMiddleware, workspaceHandler.ListMembers)
+	r.POST("/workspaces/:id/members", authMiddleware, workspaceHandler.AddMember)
+	r.PATCH("/workspaces/:id/members/:user_id", authMiddleware, workspaceHandler.UpdateMember)
+	r.DELETE("/workspaces/:id/members/:user_id", authMiddleware, workspaceHandler.RemoveMember)
+
 	// Public URL shortener routes
 	r.GET("/:alias", urlHandler.RedirectURL)
+	r.HEAD("/:alias", urlHandler.RedirectURL)
+	r.POST("/:alias", urlHandler.UnlockURL)
+
+	// Protected URL shortener routes (require authentication, or an API key
+	// with the links:read or links:write scope)
+	r.POST("/url/shorten", linksWriteAuth, urlHandler.ShortenURL)
+	r.GET("/url/links/:alias", linksReadAuth, urlHandler.GetURLInfo)
+	r.PATCH("/url/links/:alias", linksWriteAuth, urlHandler.UpdateURL)
+	r.DELETE("/url/links/:alias", linksWriteAuth, urlHandler.DeleteURL)
+	r.GET("/url/my-links", linksReadAuth, urlHandler.GetUserURLs)
 
-	// Protected URL shortener routes (require authentication)
-	r.POST("/url/shorten", authMiddleware, urlHandler.ShortenURL)
-	r.GET("/url/links/:alias", authMiddleware, urlHandler.GetURLInfo)
-	r.GET("/url/my-links", authMiddleware, urlHandler.GetUserURLs)
+	// Protected analytics routes (require authentication or an API key with
+	// the stats:read scope, owner or workspace members only)
+	r.GET("/url/links/:alias/stats/timeseries", statsReadAuth, analyticsHandler.GetTimeSeries)
+	r.GET("/url/links/:alias/stats/uniques", statsReadAuth, analyticsHandler.GetUniqueVisitors)
+	r.GET("/url/links/:alias/stats/referrers", statsReadAuth, analyticsHandler.GetReferrers)
+	r.GET("/url/links/:alias/stats/devices", statsReadAuth, analyticsHandler.GetDevices)
+	r.GET("/url/links/:alias/stats/geo", statsReadAuth, analyticsHandler.GetGeo)
+	r.GET("/url/links/:alias/stats/rules", statsReadAuth, analyticsHandler.GetRules)
+	r.GET("/url/links/:alias/stats/variants", statsReadAuth, analyticsHandler.GetVariants)
 
-	// Admin routes (require authentication)
-	r.GET("/admin/url", urlHandler.ListURLs)
+	// Admin routes (require authentication and at least the moderator role;
+	// everything but reviewing URLs is for admins only)
+	admin := r.Group("/admin", authMiddleware, middleware.RequireRole(userService, domain.RoleModerator))
+	admin.GET("/url", urlHandler.ListURLs)
+	admin.DELETE("/url/:alias", requireAdmin, urlHandler.PurgeURL)
+	admin.GET("/clicks", requireAdmin, adminHandler.GetClickPipeline)
+	admin.GET("/users", requireAdmin, adminHandler.ListUsers)
+	admin.PATCH("/users/:id/role", requireAdmin, adminHandler.UpdateUserRole)
 
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -1,15 +1,25 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/Faleeeee/URL_Shortener/internal/database"
+	"github.com/Faleeeee/URL_Shortener/internal/geoip"
+	"github.com/Faleeeee/URL_Shortener/internal/repository"
+	"github.com/Faleeeee/URL_Shortener/internal/service"
 
 	"github.com/Faleeeee/URL_Shortener/internal/config"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 type Server struct {
 	cfg *config.Config
 	db  *database.DB
@@ -31,10 +41,90 @@ func (s *Server) Run() {
 		jwtExpiration = 24 * time.Hour
 	}
 
-	r := NewRouter(s.db, baseURL, s.cfg.JWT.Secret, jwtExpiration, s.cfg.Shortener.Base62Chars)
+	clickFlushInterval, err := time.ParseDuration(s.cfg.Analytics.ClickFlushInterval)
+	if err != nil {
+		log.Printf("Invalid click flush interval format, using default %s: %v", service.DefaultClickFlushInterval, err)
+		clickFlushInterval = service.DefaultClickFlushInterval
+	}
+
+	geoReloadInterval, err := time.ParseDuration(s.cfg.GeoIP.ReloadInterval)
+	if err != nil {
+		log.Printf("Invalid GeoIP reload interval format, using default %s: %v", geoip.DefaultReloadInterval, err)
+		geoReloadInterval = geoip.DefaultReloadInterval
+	}
 
-	log.Printf("Server running on %s", baseURL)
-	log.Printf("Swagger docs: %s/swagger/index.html", baseURL)
+	urlCacheTTL, err := time.ParseDuration(s.cfg.Cache.URLTTL)
+	if err != nil {
+		log.Printf("Invalid URL cache TTL format, using default %s: %v", repository.DefaultURLCacheTTL, err)
+		urlCacheTTL = repository.DefaultURLCacheTTL
+	}
 
-	http.ListenAndServe(":"+s.cfg.Server.Port, r)
+	urlCacheNegativeTTL, err := time.ParseDuration(s.cfg.Cache.URLNegativeTTL)
+	if err != nil {
+		log.Printf("Invalid URL cache negative TTL format, using default %s: %v", repository.DefaultURLCacheNegativeTTL, err)
+		urlCacheNegativeTTL = repository.DefaultURLCacheNegativeTTL
+	}
+
+	unlockTTL, err := time.ParseDuration(s.cfg.LinkPassword.UnlockTTL)
+	if err != nil {
+		log.Printf("Invalid link unlock TTL format, using default %s: %v", service.DefaultUnlockTTL, err)
+		unlockTTL = service.DefaultUnlockTTL
+	}
+
+	passwordAttemptWindow, err := time.ParseDuration(s.cfg.LinkPassword.AttemptWindow)
+	if err != nil {
+		log.Printf("Invalid link password attempt window format, using default %s: %v", service.DefaultPasswordAttemptWindow, err)
+		passwordAttemptWindow = service.DefaultPasswordAttemptWindow
+	}
+
+	// Password checks for protected links
+	linkGuard := service.NewLinkGuard(
+		s.cfg.LinkPassword.UnlockSecret,
+		unlockTTL,
+		s.cfg.LinkPassword.MaxAttempts,
+		passwordAttemptWindow,
+	)
+
+	// GeoIP database, reloaded when the file changes
+	geoResolver := geoip.NewResolver(s.cfg.GeoIP.DatabasePath, geoReloadInterval)
+	defer geoResolver.Close()
+
+	// Background click writer, flushed on shutdown
+	clickWriter := service.NewClickWriter(
+		repository.NewClickRepository(s.db),
+		s.cfg.Analytics.ClickBufferSize,
+		s.cfg.Analytics.ClickBatchSize,
+		clickFlushInterval,
+	)
+	defer clickWriter.Close()
+
+	r := NewRouter(s.cfg, s.db, clickWriter, geoResolver, linkGuard, jwtExpiration, urlCacheTTL, urlCacheNegativeTTL)
+
+	srv := &http.Server{
+		Addr:    ":" + s.cfg.Server.Port,
+		Handler: r,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("Server running on %s", baseURL)
+		log.Printf("Swagger docs: %s/swagger/index.html", baseURL)
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Server error: %v", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server forced to shut down: %v", err)
+	}
 }
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/geoip"
+	"github.com/Faleeeee/URL_Shortener/internal/hll"
+	"github.com/Faleeeee/URL_Shortener/internal/repository"
+	"github.com/Faleeeee/URL_Shortener/internal/useragent"
+)
+
+// AnalyticsService defines the interface for click analytics
+type AnalyticsService interface {
+	EnrichVisit(visit *domain.Visit)
+	RecordClick(url *domain.URL, visit *domain.Visit)
+	GetTimeSeries(url *domain.URL, q *domain.TimeSeriesQuery) (*domain.TimeSeriesResponse, error)
+	GetBreakdown(url *domain.URL, dimension string, includeBots bool, limit, offset int) ([]domain.BreakdownItem, int64, error)
+	GetUniqueVisitors(url *domain.URL, from, to time.Time) (*domain.UniqueVisitorsResponse, error)
+	GetVariantStats(url *domain.URL, includeBots bool) (*domain.VariantStatsResponse, error)
+}
+
+type analyticsService struct {
+	repo       repository.ClickRepository
+	clicks     *ClickWriter
+	uaParser   *useragent.Parser
+	geo        *geoip.Resolver
+	ipHashSalt []byte
+	selfHost   string
+	domains    *DomainService
+}
+
+// NewAnalyticsService creates a new analytics service. baseURL and the
+// verified custom domains are used to recognise clicks referred by the
+// shortener itself.
+func NewAnalyticsService(repo repository.ClickRepository, clicks *ClickWriter, uaParser *useragent.Parser, geo *geoip.Resolver, domains *DomainService, ipHashSalt, baseURL string) AnalyticsService {
+	return &analyticsService{
+		repo:       repo,
+		clicks:     clicks,
+		uaParser:   uaParser,
+		geo:        geo,
+		ipHashSalt: []byte(ipHashSalt),
+		selfHost:   normalizeHost(baseURL),
+		domains:    domains,
+	}
+}
+
+// EnrichVisit classifies the visitor's device, OS, browser and platform, flags
+// crawlers, link previewers and prefetches as bots, resolves the visitor IP to
+// a location and hashes the visitor's IP and User-Agent
+func (s *analyticsService) EnrichVisit(visit *domain.Visit) {
+	ua := s.uaParser.Parse(visit.UserAgent)
+	visit.Device = ua.Device
+	visit.OS = ua.OS
+	visit.Browser = ua.Browser
+	visit.IsBot = ua.IsBot() || isAutomatedRequest(visit)
+	visit.Unfurler = ua.Unfurler
+	if !visit.IsBot {
+		visit.Platform = detectPlatform(ua, visit.PlatformHint, visit.MobileHint)
+	}
+
+	loc := s.geo.Lookup(visit.IP)
+	visit.Country = loc.Country
+	visit.Region = loc.Region
+	visit.City = loc.City
+
+	visit.VisitorHash = s.hashVisitor(visit.IP, visit.UserAgent)
+}
+
+// RecordClick queues a click event for an enriched visit. The URL's click
+// counters are incremented when the event is written, so a dropped event is
+// not counted either. The visitor IP is only stored as a salted hash, and
+// visitors are told apart for unique visitor counts by a salted hash of IP and
+// User-Agent.
+func (s *analyticsService) RecordClick(url *domain.URL, visit *domain.Visit) {
+	s.clicks.Record(&domain.ClickEvent{
+		URLID:          url.ID,
+		Alias:          url.Alias,
+		ClickedAt:      visit.Time,
+		Referrer:       visit.Referrer,
+		UserAgent:      visit.UserAgent,
+		IPHash:         s.hashIP(visit.IP),
+		AcceptLanguage: visit.AcceptLanguage,
+		ReferrerHost:   s.referrerHost(visit.Referrer),
+		UTMSource:      visit.Query.Get("utm_source"),
+		UTMMedium:      visit.Query.Get("utm_medium"),
+		UTMCampaign:    visit.Query.Get("utm_campaign"),
+		DeviceClass:    visit.Device,
+		OSFamily:       visit.OS,
+		BrowserFamily:  visit.Browser,
+		Country:        visit.Country,
+		Region:         visit.Region,
+		City:           visit.City,
+		IsBot:          visit.IsBot,
+		MatchedRule:    visit.MatchedRule,
+		Variant:        visit.Variant,
+		VisitorHash:    visit.VisitorHash,
+	})
+}
+
+// GetTimeSeries returns the clicks on a URL bucketed by hour, day or week,
+// including empty buckets
+func (s *analyticsService) GetTimeSeries(url *domain.URL, q *domain.TimeSeriesQuery) (*domain.TimeSeriesResponse, error) {
+	if err := domain.ValidateTimeSeriesQuery(q); err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.CountByInterval(url.ID, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time series: %w", err)
+	}
+
+	return &domain.TimeSeriesResponse{
+		Alias:    url.Alias,
+		From:     q.From.In(q.Location),
+		To:       q.To.In(q.Location),
+		Interval: q.Interval,
+		Timezone: q.Location.String(),
+		Points:   points,
+	}, nil
+}
+
+// GetBreakdown returns the clicks on a URL grouped by a breakdown dimension,
+// most clicked first, along with the number of distinct values
+func (s *analyticsService) GetBreakdown(url *domain.URL, dimension string, includeBots bool, limit, offset int) ([]domain.BreakdownItem, int64, error) {
+	// Set default limit if not specified
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// Prevent excessive limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, total, err := s.repo.CountByDimension(url.ID, dimension, includeBots, limit, offset)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidBreakdown) {
+			return nil, 0, err
+		}
+		return nil, 0, fmt.Errorf("failed to get breakdown: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// GetUniqueVisitors returns the approximate number of distinct human visitors
+// of a URL on each UTC day touched by [from, to), including days without
+// visitors, and over the whole range
+func (s *analyticsService) GetUniqueVisitors(url *domain.URL, from, to time.Time) (*domain.UniqueVisitorsResponse, error) {
+	if !from.Before(to) {
+		return nil, domain.ErrInvalidTimeRange
+	}
+
+	firstDay := from.UTC().Truncate(24 * time.Hour)
+	lastDay := to.UTC().Add(-time.Nanosecond).Truncate(24 * time.Hour)
+	if lastDay.Sub(firstDay)/(24*time.Hour) >= domain.MaxTimeSeriesBuckets {
+		return nil, domain.ErrTimeRangeTooLarge
+	}
+
+	sketches, err := s.repo.FindDailyVisitorSketches(url.ID, firstDay, lastDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unique visitors: %w", err)
+	}
+
+	total := hll.New()
+	points := make([]domain.UniqueVisitorsPoint, 0, len(sketches))
+	for _, daily := range sketches {
+		sketch, err := hll.Decode(daily.Sketch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode visitor sketch for %s: %w", daily.Day.Format("2006-01-02"), err)
+		}
+		total.Merge(sketch)
+		points = append(points, domain.UniqueVisitorsPoint{
+			Day:            daily.Day,
+			UniqueVisitors: sketch.Estimate(),
+		})
+	}
+
+	return &domain.UniqueVisitorsResponse{
+		Alias:          url.Alias,
+		From:           firstDay,
+		To:             lastDay.Add(24 * time.Hour),
+		UniqueVisitors: total.Estimate(),
+		Points:         points,
+	}, nil
+}
+
+// GetVariantStats returns the clicks on each variant of a URL, including
+// variants without clicks yet. Clicks made before the URL had variants are
+// not reported.
+func (s *analyticsService) GetVariantStats(url *domain.URL, includeBots bool) (*domain.VariantStatsResponse, error) {
+	items, _, err := s.repo.CountByDimension(url.ID, domain.BreakdownVariant, includeBots, 100, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant stats: %w", err)
+	}
+
+	clicks := make(map[string]int64, len(items))
+	for _, item := range items {
+		if item.Value != domain.BreakdownNone {
+			clicks[item.Value] = item.Clicks
+		}
+	}
+
+	response := &domain.VariantStatsResponse{
+		Alias:    url.Alias,
+		Variants: make([]domain.VariantStats, 0, len(url.Variants)),
+	}
+	for _, variant := range url.Variants {
+		response.Variants = append(response.Variants, domain.VariantStats{
+			Name:   variant.Name,
+			URL:    variant.URL,
+			Weight: variant.Weight,
+			Clicks: clicks[variant.Name],
+		})
+		response.Clicks += clicks[variant.Name]
+		delete(clicks, variant.Name)
+	}
+
+	// Variants that were removed or renamed, most clicked first as returned
+	for _, item := range items {
+		if n, ok := clicks[item.Value]; ok {
+			response.Variants = append(response.Variants, domain.VariantStats{Name: item.Value, Clicks: n})
+			response.Clicks += n
+		}
+	}
+
+	return response, nil
+}
+
+// isAutomatedRequest reports whether a request was made without a person
+// following the link: HEAD requests from link checkers, and browser
+// prefetches or link previews announced through a purpose header
+func isAutomatedRequest(visit *domain.Visit) bool {
+	if visit.Method == "HEAD" {
+		return true
+	}
+
+	purpose := strings.ToLower(visit.Purpose)
+	return strings.Contains(purpose, "prefetch") ||
+		strings.Contains(purpose, "prerender") ||
+		strings.Contains(purpose, "preview")
+}
+
+// detectPlatform maps a visit to the platform targeted by device rules. The
+// Sec-CH-UA-Platform and Sec-CH-UA-Mobile Client Hints win over the
+// User-Agent, which Chromium browsers freeze to a generic value. Visits whose
+// platform cannot be told get an empty string and match no device rule.
+func detectPlatform(ua useragent.Info, platformHint, mobileHint string) string {
+	switch strings.Trim(platformHint, `" `) {
+	case "iOS":
+		return domain.PlatformIOS
+	case "Android":
+		return domain.PlatformAndroid
+	case "Windows", "macOS", "Linux", "Chrome OS", "Chromium OS":
+		if mobileHint != "?1" {
+			return domain.PlatformDesktop
+		}
+	}
+
+	switch ua.OS {
+	case "iOS":
+		return domain.PlatformIOS
+	case "Android":
+		return domain.PlatformAndroid
+	case "Windows", "macOS", "Linux", "Chrome OS":
+		if ua.Device == useragent.DeviceDesktop {
+			return domain.PlatformDesktop
+		}
+	}
+
+	return ""
+}
+
+// referrerHost classifies a Referer header as a normalized host, or as a
+// direct, unknown or self referral
+func (s *analyticsService) referrerHost(referrer string) string {
+	if referrer == "" {
+		return domain.ReferrerDirect
+	}
+
+	host := normalizeHost(referrer)
+	if host == "" {
+		return domain.ReferrerUnknown
+	}
+	if host == s.selfHost {
+		return domain.ReferrerSelf
+	}
+	if s.domains.IsVerifiedHost(referrerHostname(referrer)) {
+		return domain.ReferrerSelf
+	}
+
+	return host
+}
+
+// normalizeHost returns the lower-cased host of an absolute URL without port
+// or leading "www.", or an empty string if there is none
+func normalizeHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// referrerHostname returns the host of an absolute URL without port, as
+// custom domains are registered
+func referrerHostname(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// hashIP returns a keyed hash of an IP address, so visitors can be told apart
+// without storing their address
+func (s *analyticsService) hashIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.ipHashSalt)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// hashVisitor returns a keyed 64-bit hash of a visitor's IP address and
+// User-Agent for unique visitor sketches, or zero if both are unknown
+func (s *analyticsService) hashVisitor(ip, userAgent string) uint64 {
+	if ip == "" && userAgent == "" {
+		return 0
+	}
+	mac := hmac.New(sha256.New, s.ipHashSalt)
+	mac.Write([]byte(ip))
+	mac.Write([]byte{0})
+	mac.Write([]byte(userAgent))
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/repository"
+)
+
+const (
+	// apiKeySecretBytes is the number of random bytes in an API key
+	apiKeySecretBytes = 32
+
+	// apiKeyLastUsedResolution bounds how often the last use of a key is
+	// written, so busy keys do not cause a write per request
+	apiKeyLastUsedResolution = time.Minute
+)
+
+// APIKeyService manages the API keys of users and authenticates requests
+// made with them
+type APIKeyService struct {
+	repo *repository.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create generates a new API key for a user. The returned key is the only
+// copy of it; only its hash is stored.
+func (s *APIKeyService) Create(userID int64, req *domain.CreateAPIKeyRequest) (*domain.APIKey, string, error) {
+	name, err := domain.NormalizeAPIKeyName(req.Name)
+	if err != nil {
+		return nil, "", err
+	}
+	scopes, err := domain.NormalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	rawKey := domain.APIKeyPrefix + hex.EncodeToString(secret)
+
+	key := &domain.APIKey{
+		UserID:  userID,
+		Name:    name,
+		Prefix:  rawKey[:domain.APIKeyDisplayLength],
+		KeyHash: hashAPIKey(rawKey),
+		Scopes:  scopes,
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, "", err
+	}
+
+	return key, rawKey, nil
+}
+
+// List returns the API keys of a user, revoked ones included
+func (s *APIKeyService) List(userID int64) ([]*domain.APIKey, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+// Revoke disables an API key of a user for good
+func (s *APIKeyService) Revoke(userID, id int64) error {
+	if err := s.repo.Revoke(userID, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrAPIKeyNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Authenticate returns the unrevoked API key matching rawKey and records its
+// use
+func (s *APIKeyService) Authenticate(rawKey string) (*domain.APIKey, error) {
+	if !strings.HasPrefix(rawKey, domain.APIKeyPrefix) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.FindActiveByHash(hashAPIKey(rawKey))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedResolution {
+		// Failing to record the use must not fail the request
+		if err := s.repo.TouchLastUsed(key.ID); err != nil {
+			log.Printf("Failed to record use of API key %d: %v", key.ID, err)
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 of an API key. Keys are long
+// random strings, so a fast unsalted hash is enough and allows lookups.
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
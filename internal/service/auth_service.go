@@ -1,6 +1,9 @@
 package service
 
 import (
+	"fmt"
+	"log"
+
 	"github.com/Faleeeee/URL_Shortener/internal/domain"
 	"github.com/Faleeeee/URL_Shortener/internal/repository"
 	"github.com/Faleeeee/URL_Shortener/internal/utils"
@@ -41,7 +44,7 @@ func (s *AuthService) Register(username, password string) (*domain.AuthResponse,
 	}
 
 	// Create user
-	user, err := s.userRepo.CreateUser(username, string(hashedPassword))
+	user, err := s.userRepo.CreateUser(username, string(hashedPassword), domain.RoleUser)
 	if err != nil {
 		if err == repository.ErrDuplicateUsername {
 			return nil, domain.ErrUsernameAlreadyExists
@@ -50,7 +53,7 @@ func (s *AuthService) Register(username, password string) (*domain.AuthResponse,
 	}
 
 	// Generate JWT token
-	token, err := s.jwtManager.GenerateToken(user.ID, user.Username)
+	token, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +62,7 @@ func (s *AuthService) Register(username, password string) (*domain.AuthResponse,
 		Token:    token,
 		Username: user.Username,
 		UserID:   user.ID,
+		Role:     user.Role,
 	}, nil
 }
 
@@ -80,7 +84,7 @@ func (s *AuthService) Login(username, password string) (*domain.AuthResponse, er
 	}
 
 	// Generate JWT token
-	token, err := s.jwtManager.GenerateToken(user.ID, user.Username)
+	token, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -89,5 +93,94 @@ func (s *AuthService) Login(username, password string) (*domain.AuthResponse, er
 		Token:    token,
 		Username: user.Username,
 		UserID:   user.ID,
+		Role:     user.Role,
 	}, nil
 }
+
+// SeedAdmins promotes the given users to admin while no user is an admin yet,
+// so deployments that listed their admins in ADMIN_USERNAMES keep them. Only
+// accounts created before roles existed are promoted: a listed name that was
+// registered afterwards could belong to anyone. Once an admin exists, roles
+// are only managed through the admin endpoints and demotions stick across
+// restarts.
+func (s *AuthService) SeedAdmins(usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	exists, err := s.userRepo.HasRole(domain.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to look up admins: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	for _, username := range usernames {
+		promoted, err := s.userRepo.PromoteLegacyAdmin(username)
+		if err != nil {
+			return fmt.Errorf("failed to promote %s to admin: %w", username, err)
+		}
+		if !promoted {
+			log.Printf("Admin user %s does not exist or was created after roles were added, skipping", username)
+			continue
+		}
+		log.Printf("Promoted existing user %s to admin", username)
+	}
+
+	return nil
+}
+
+// BootstrapAdmin makes sure there is a first admin to manage roles with. If no
+// user is an admin yet, the user with username is created with password. An
+// existing user is only promoted when its password is the configured one, as
+// anyone can register the name before the first start. It does nothing when
+// username is empty or an admin already exists, so it is safe to run on every
+// startup.
+func (s *AuthService) BootstrapAdmin(username, password string) error {
+	if username == "" {
+		return nil
+	}
+
+	exists, err := s.userRepo.HasRole(domain.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to look up admins: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	user, err := s.userRepo.GetUserByUsername(username)
+	if err == nil {
+		if password == "" || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+			return fmt.Errorf("user %s already exists and its password does not match ADMIN_BOOTSTRAP_PASSWORD, refusing to promote it", username)
+		}
+		if err := s.userRepo.UpdateRole(user.ID, domain.RoleAdmin); err != nil {
+			return fmt.Errorf("failed to promote %s to admin: %w", username, err)
+		}
+		log.Printf("Promoted existing user %s to admin", username)
+		return nil
+	}
+	if err != repository.ErrNotFound {
+		return fmt.Errorf("failed to look up %s: %w", username, err)
+	}
+
+	if err := domain.ValidateUsername(username); err != nil {
+		return err
+	}
+	if err := domain.ValidatePassword(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.userRepo.CreateUser(username, string(hashedPassword), domain.RoleAdmin); err != nil {
+		return fmt.Errorf("failed to create admin %s: %w", username, err)
+	}
+	log.Printf("Created admin user %s", username)
+
+	return nil
+}
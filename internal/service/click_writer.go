@@ -0,0 +1,215 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/repository"
+)
+
+const (
+	DefaultClickBufferSize    = 10000
+	DefaultClickBatchSize     = 500
+	DefaultClickFlushInterval = time.Second
+
+	// clickWriteAttempts is how often a batch is tried as a whole, with
+	// clickRetryDelay doubling in between, before it is split to isolate
+	// the events that cannot be written
+	clickWriteAttempts = 3
+	clickRetryDelay    = 100 * time.Millisecond
+)
+
+// ClickWriter buffers click events in memory and writes them to the database
+// in batches from a single goroutine, together with the click counters of
+// their URLs. A viral link then costs one row update per batch instead of one
+// per click. The buffer is bounded: when it is full, new events are dropped
+// instead of blocking the redirect, and are not counted either. A batch that
+// fails is retried, then split, so one bad event only loses itself.
+type ClickWriter struct {
+	repo          repository.ClickRepository
+	events        chan *domain.ClickEvent
+	batchSize     int
+	flushInterval time.Duration
+	retryDelay    time.Duration
+	dropped       atomic.Int64
+	failed        atomic.Int64
+
+	closing   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewClickWriter creates a click writer and starts its background goroutine
+func NewClickWriter(repo repository.ClickRepository, bufferSize, batchSize int, flushInterval time.Duration) *ClickWriter {
+	if bufferSize <= 0 {
+		bufferSize = DefaultClickBufferSize
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultClickBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultClickFlushInterval
+	}
+
+	w := &ClickWriter{
+		repo:          repo,
+		events:        make(chan *domain.ClickEvent, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		retryDelay:    clickRetryDelay,
+		closing:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go w.run()
+
+	return w
+}
+
+// Record queues a click event without blocking. The event is dropped if the
+// buffer is full or the writer is shutting down.
+func (w *ClickWriter) Record(event *domain.ClickEvent) {
+	select {
+	case <-w.closing:
+		w.dropped.Add(1)
+		return
+	default:
+	}
+
+	select {
+	case w.events <- event:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// Pending returns the number of click events waiting in the buffer
+func (w *ClickWriter) Pending() int64 {
+	return int64(len(w.events))
+}
+
+// Dropped returns the number of click events dropped since startup
+func (w *ClickWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Failed returns the number of click events that could not be written since
+// startup
+func (w *ClickWriter) Failed() int64 {
+	return w.failed.Load()
+}
+
+// Close stops accepting events, writes everything still buffered and waits
+// for the background goroutine to finish
+func (w *ClickWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closing)
+	})
+	<-w.done
+	return nil
+}
+
+func (w *ClickWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	var lastDropped int64
+	batch := make([]*domain.ClickEvent, 0, w.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		w.write(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case event := <-w.events:
+			batch = append(batch, event)
+			if len(batch) >= w.batchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+			if dropped := w.dropped.Load(); dropped > lastDropped {
+				log.Printf("Dropped %d click events because the buffer was full", dropped-lastDropped)
+				lastDropped = dropped
+			}
+
+		case <-w.closing:
+			// Drain whatever is left in the buffer before exiting
+			for {
+				select {
+				case event := <-w.events:
+					batch = append(batch, event)
+					if len(batch) >= w.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// write stores a batch, retrying it as a whole to ride out transient errors.
+// If it still fails, it is split in halves until the events that cannot be
+// written are isolated; only those are lost.
+func (w *ClickWriter) write(batch []*domain.ClickEvent) {
+	delay := w.retryDelay
+	var err error
+	for attempt := 1; attempt <= clickWriteAttempts; attempt++ {
+		if err = w.repo.InsertBatch(batch); err == nil {
+			return
+		}
+		if attempt < clickWriteAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	failed := 1
+	if len(batch) > 1 {
+		failed, err = w.split(batch)
+	}
+	if failed > 0 {
+		w.failed.Add(int64(failed))
+		log.Printf("Failed to write %d of %d click events: %v", failed, len(batch), err)
+	}
+}
+
+// split writes the halves of a failed batch separately, recursing into the
+// halves that fail too. It returns the number of events that could not be
+// written, and the last error.
+func (w *ClickWriter) split(batch []*domain.ClickEvent) (int, error) {
+	mid := len(batch) / 2
+	var (
+		failed  int
+		lastErr error
+	)
+	for _, half := range [][]*domain.ClickEvent{batch[:mid], batch[mid:]} {
+		err := w.repo.InsertBatch(half)
+		if err == nil {
+			continue
+		}
+		if len(half) == 1 {
+			failed++
+			lastErr = err
+			continue
+		}
+		n, err := w.split(half)
+		failed += n
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return failed, lastErr
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/repository"
+)
+
+var errBadEvent = errors.New("bad event")
+
+// fakeClickRepository stores click events in memory. Batches holding an event
+// of a bad URL fail as a whole, like a COPY does, and the first few batches
+// fail whatever they hold while failures is positive. Queries are left to the
+// embedded nil interface.
+type fakeClickRepository struct {
+	repository.ClickRepository
+
+	mu       sync.Mutex
+	bad      map[int64]bool
+	failures int
+	stored   []*domain.ClickEvent
+	calls    int
+}
+
+func (f *fakeClickRepository) InsertBatch(events []*domain.ClickEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("connection refused")
+	}
+	for _, event := range events {
+		if f.bad[event.URLID] {
+			return errBadEvent
+		}
+	}
+	f.stored = append(f.stored, events...)
+	return nil
+}
+
+func newTestClickWriter(repo repository.ClickRepository) *ClickWriter {
+	return &ClickWriter{repo: repo, retryDelay: time.Millisecond}
+}
+
+func clickEvents(urlIDs ...int64) []*domain.ClickEvent {
+	events := make([]*domain.ClickEvent, len(urlIDs))
+	for i, id := range urlIDs {
+		events[i] = &domain.ClickEvent{URLID: id}
+	}
+	return events
+}
+
+func TestClickWriterWrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		urlIDs     []int64
+		bad        []int64
+		failures   int
+		wantStored int
+		wantFailed int64
+	}{
+		{name: "healthy batch", urlIDs: []int64{1, 2, 3, 4}, wantStored: 4},
+		{name: "transient error is retried", urlIDs: []int64{1, 2, 3}, failures: clickWriteAttempts - 1, wantStored: 3},
+		{name: "one bad event", urlIDs: []int64{1, 2, 3, 4, 5, 6, 7}, bad: []int64{5}, wantStored: 6, wantFailed: 1},
+		{name: "several bad events", urlIDs: []int64{9, 1, 2, 9, 3, 4, 9, 5}, bad: []int64{9}, wantStored: 5, wantFailed: 3},
+		{name: "single bad event", urlIDs: []int64{9}, bad: []int64{9}, wantStored: 0, wantFailed: 1},
+		{name: "all bad", urlIDs: []int64{9, 9, 9}, bad: []int64{9}, wantStored: 0, wantFailed: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeClickRepository{bad: make(map[int64]bool), failures: tt.failures}
+			for _, id := range tt.bad {
+				repo.bad[id] = true
+			}
+			w := newTestClickWriter(repo)
+
+			w.write(clickEvents(tt.urlIDs...))
+
+			if got := len(repo.stored); got != tt.wantStored {
+				t.Errorf("stored %d events, want %d", got, tt.wantStored)
+			}
+			if got := w.Failed(); got != tt.wantFailed {
+				t.Errorf("Failed() = %d, want %d", got, tt.wantFailed)
+			}
+			for _, event := range repo.stored {
+				if repo.bad[event.URLID] {
+					t.Errorf("stored an event of bad URL %d", event.URLID)
+				}
+			}
+		})
+	}
+}
+
+func TestClickWriterFlushesOnClose(t *testing.T) {
+	repo := &fakeClickRepository{bad: map[int64]bool{9: true}}
+	w := NewClickWriter(repo, 100, 10, time.Hour)
+
+	for _, event := range clickEvents(1, 2, 9, 3) {
+		w.Record(event)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if len(repo.stored) != 3 {
+		t.Errorf("stored %d events after Close, want 3", len(repo.stored))
+	}
+	if w.Failed() != 1 {
+		t.Errorf("Failed() = %d, want 1", w.Failed())
+	}
+}
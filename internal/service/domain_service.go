@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/repository"
+)
+
+const (
+	DefaultDomainLookupTTL       = 30 * time.Second
+	DefaultDomainVerifyTimeout   = 5 * time.Second
+	domainVerificationTokenBytes = 16
+
+	// maxCachedHosts bounds the host lookup cache
+	maxCachedHosts = 10000
+)
+
+// TXTResolver looks up DNS TXT records. *net.Resolver implements it; tests
+// can plug in a fake.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DomainService manages the custom domains of users and maps request hosts
+// to them. Host lookups are cached in memory, since every redirect needs one.
+type DomainService struct {
+	repo      *repository.DomainRepository
+	resolver  TXTResolver
+	baseHost  string
+	lookupTTL time.Duration
+
+	mu    sync.RWMutex
+	hosts map[string]hostEntry
+
+	// verified holds the hostnames of all verified domains, so referrers can
+	// be classified without a query per Referer header. It is reloaded in
+	// the background once it is lookupTTL old.
+	verifiedMu sync.Mutex
+	verified   map[string]struct{}
+	verifiedAt time.Time
+	reloading  bool
+}
+
+// hostEntry caches the verified domain ID of a host, or zero for hosts that
+// are not a verified custom domain
+type hostEntry struct {
+	domainID  int64
+	expiresAt time.Time
+}
+
+// NewDomainService creates a domain service. A nil resolver uses the system
+// DNS resolver. baseURL is the shortener's own address, which cannot be
+// registered as a custom domain.
+func NewDomainService(repo *repository.DomainRepository, resolver TXTResolver, baseURL string, lookupTTL time.Duration) *DomainService {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	if lookupTTL <= 0 {
+		lookupTTL = DefaultDomainLookupTTL
+	}
+
+	var baseHost string
+	if parsed, err := url.Parse(baseURL); err == nil {
+		baseHost = domain.NormalizeHostname(parsed.Host)
+	}
+
+	return &DomainService{
+		repo:      repo,
+		resolver:  resolver,
+		baseHost:  baseHost,
+		lookupTTL: lookupTTL,
+		hosts:     make(map[string]hostEntry),
+		verified:  make(map[string]struct{}),
+	}
+}
+
+// Register adds an unverified custom domain for a user, with a fresh
+// verification token
+func (s *DomainService) Register(userID int64, hostname string) (*domain.CustomDomain, error) {
+	hostname = domain.NormalizeHostname(hostname)
+	if err := domain.ValidateHostname(hostname); err != nil {
+		return nil, err
+	}
+	if hostname == s.baseHost {
+		return nil, domain.ErrReservedHostname
+	}
+
+	token := make([]byte, domainVerificationTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	d := &domain.CustomDomain{
+		UserID:            userID,
+		Hostname:          hostname,
+		VerificationToken: hex.EncodeToString(token),
+	}
+	if err := s.repo.Create(d); err != nil {
+		if errors.Is(err, repository.ErrDuplicateDomain) {
+			return nil, domain.ErrDomainExists
+		}
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// List returns the custom domains of a user
+func (s *DomainService) List(userID int64) ([]*domain.CustomDomain, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+// Verify checks the verification TXT record of a user's domain and marks the
+// domain verified once the record holds its token. Verifying an already
+// verified domain is a no-op.
+func (s *DomainService) Verify(userID int64, hostname string) (*domain.CustomDomain, error) {
+	d, err := s.find(userID, hostname)
+	if err != nil {
+		return nil, err
+	}
+	if d.IsVerified() {
+		return d, nil
+	}
+
+	record := d.VerificationRecord()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultDomainVerifyTimeout)
+	defer cancel()
+
+	values, err := s.resolver.LookupTXT(ctx, record.Name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && (dnsErr.IsNotFound || dnsErr.IsTimeout || dnsErr.IsTemporary) {
+			return nil, domain.ErrVerificationFailed
+		}
+		return nil, fmt.Errorf("failed to look up verification record: %w", err)
+	}
+
+	found := false
+	for _, value := range values {
+		if strings.TrimSpace(value) == record.Value {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, domain.ErrVerificationFailed
+	}
+
+	if err := s.repo.MarkVerified(d); err != nil {
+		if errors.Is(err, repository.ErrDomainVerified) {
+			return nil, domain.ErrDomainTaken
+		}
+		return nil, err
+	}
+	s.forget(d.Hostname)
+	s.setVerified(d.Hostname, true)
+
+	return d, nil
+}
+
+// Delete removes a user's domain. Domains that still have URLs cannot be
+// removed, including soft-deleted URLs, which keep their alias and analytics
+// until their owner purges them.
+func (s *DomainService) Delete(userID int64, hostname string) error {
+	d, err := s.find(userID, hostname)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(d.ID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return domain.ErrDomainNotFound
+		case errors.Is(err, repository.ErrDomainHasURLs):
+			return domain.ErrDomainInUse
+		}
+		return err
+	}
+	s.forget(d.Hostname)
+	s.setVerified(d.Hostname, false)
+
+	return nil
+}
+
+// FindForLinks returns a user's domain for serving new URLs from, which must
+// be verified
+func (s *DomainService) FindForLinks(userID int64, hostname string) (*domain.CustomDomain, error) {
+	d, err := s.find(userID, hostname)
+	if err != nil {
+		return nil, err
+	}
+	if !d.IsVerified() {
+		return nil, domain.ErrDomainNotVerified
+	}
+	return d, nil
+}
+
+// LookupHost maps the Host of a request to the ID of the verified custom
+// domain it belongs to. The shortener's own host, and hosts that are not a
+// verified custom domain, map to zero, which stands for BASE_URL.
+func (s *DomainService) LookupHost(host string) (int64, error) {
+	host = domain.NormalizeHostname(host)
+	if host == "" || host == s.baseHost {
+		return 0, nil
+	}
+
+	now := time.Now()
+	s.mu.RLock()
+	entry, ok := s.hosts[host]
+	s.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.domainID, nil
+	}
+
+	var domainID int64
+	d, err := s.repo.FindVerifiedByHostname(host)
+	switch {
+	case err == nil:
+		domainID = d.ID
+	case errors.Is(err, repository.ErrNotFound):
+	default:
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.sweep(now)
+	s.hosts[host] = hostEntry{domainID: domainID, expiresAt: now.Add(s.lookupTTL)}
+	s.mu.Unlock()
+
+	return domainID, nil
+}
+
+// IsVerifiedHost reports whether a host is a verified custom domain. Unlike
+// LookupHost it never queries the database on the caller's behalf, so it is
+// safe to use with hosts taken from any request header. Until the first
+// reload finishes, no host is verified.
+func (s *DomainService) IsVerifiedHost(host string) bool {
+	host = domain.NormalizeHostname(host)
+	if host == "" {
+		return false
+	}
+
+	s.verifiedMu.Lock()
+	defer s.verifiedMu.Unlock()
+
+	if !s.reloading && time.Since(s.verifiedAt) >= s.lookupTTL {
+		s.reloading = true
+		go s.reloadVerified()
+	}
+
+	_, ok := s.verified[host]
+	return ok
+}
+
+// LookupHostname returns the ID of the verified custom domain with a
+// hostname, for addressing its URLs by domain and alias. An empty hostname
+// stands for BASE_URL and maps to zero.
+func (s *DomainService) LookupHostname(hostname string) (int64, error) {
+	hostname = domain.NormalizeHostname(hostname)
+	if hostname == "" || hostname == s.baseHost {
+		return 0, nil
+	}
+
+	d, err := s.repo.FindVerifiedByHostname(hostname)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, domain.ErrDomainNotFound
+		}
+		return 0, err
+	}
+	return d.ID, nil
+}
+
+// find retrieves a user's domain by hostname
+func (s *DomainService) find(userID int64, hostname string) (*domain.CustomDomain, error) {
+	d, err := s.repo.FindByUserAndHostname(userID, domain.NormalizeHostname(hostname))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrDomainNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// reloadVerified replaces the set of verified hostnames with the one in the
+// database. On failure the old set is kept until the next reload.
+func (s *DomainService) reloadVerified() {
+	hostnames, err := s.repo.FindVerifiedHostnames()
+
+	s.verifiedMu.Lock()
+	defer s.verifiedMu.Unlock()
+
+	s.reloading = false
+	s.verifiedAt = time.Now()
+	if err != nil {
+		log.Printf("Failed to load verified domains: %v", err)
+		return
+	}
+
+	verified := make(map[string]struct{}, len(hostnames))
+	for _, hostname := range hostnames {
+		verified[hostname] = struct{}{}
+	}
+	s.verified = verified
+}
+
+// setVerified records a change of a domain in the set of verified hostnames
+// right away, rather than at the next reload
+func (s *DomainService) setVerified(hostname string, verified bool) {
+	s.verifiedMu.Lock()
+	defer s.verifiedMu.Unlock()
+
+	if verified {
+		s.verified[hostname] = struct{}{}
+	} else {
+		delete(s.verified, hostname)
+	}
+}
+
+// forget drops the cached lookup of a host after its domain changed
+func (s *DomainService) forget(host string) {
+	s.mu.Lock()
+	delete(s.hosts, host)
+	s.mu.Unlock()
+}
+
+// sweep drops expired host lookups once the cache grew large, so requests
+// with random Host headers cannot grow it without bound. It must be called
+// with s.mu held.
+func (s *DomainService) sweep(now time.Time) {
+	if len(s.hosts) < maxCachedHosts {
+		return
+	}
+	for host, entry := range s.hosts {
+		if !now.Before(entry.expiresAt) {
+			delete(s.hosts, host)
+		}
+	}
+	if len(s.hosts) >= maxCachedHosts {
+		s.hosts = make(map[string]hostEntry)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	DefaultUnlockTTL             = 15 * time.Minute
+	DefaultPasswordMaxAttempts   = 5
+	DefaultPasswordAttemptWindow = 15 * time.Minute
+)
+
+var (
+	ErrIncorrectPassword = errors.New("incorrect password")
+	ErrTooManyAttempts   = errors.New("too many incorrect passwords, try again later")
+)
+
+// LinkGuard checks the passwords of protected links and issues signed unlock
+// tokens, so a visitor who entered the right password is not asked again for
+// a while. Wrong passwords are rate limited per URL.
+type LinkGuard struct {
+	secret        []byte
+	unlockTTL     time.Duration
+	maxAttempts   int
+	attemptWindow time.Duration
+
+	mu        sync.Mutex
+	failures  map[int64]*failureWindow // by URL ID
+	nextSweep time.Time
+}
+
+// failureWindow counts the wrong passwords entered for a URL since start
+type failureWindow struct {
+	start time.Time
+	count int
+}
+
+// NewLinkGuard creates a link guard that signs unlock tokens with secret
+func NewLinkGuard(secret string, unlockTTL time.Duration, maxAttempts int, attemptWindow time.Duration) *LinkGuard {
+	if unlockTTL <= 0 {
+		unlockTTL = DefaultUnlockTTL
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultPasswordMaxAttempts
+	}
+	if attemptWindow <= 0 {
+		attemptWindow = DefaultPasswordAttemptWindow
+	}
+
+	return &LinkGuard{
+		secret:        []byte(secret),
+		unlockTTL:     unlockTTL,
+		maxAttempts:   maxAttempts,
+		attemptWindow: attemptWindow,
+		failures:      make(map[int64]*failureWindow),
+	}
+}
+
+// HashPassword hashes a link password with bcrypt, as for account passwords
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Unlock checks a password for a protected URL and returns an unlock token
+// valid until the returned expiry time. ErrTooManyAttempts is returned without
+// checking the password once too many wrong ones were entered for the URL.
+func (g *LinkGuard) Unlock(url *domain.URL, password string, now time.Time) (string, time.Time, error) {
+	if !g.allowAttempt(url.ID, now) {
+		return "", time.Time{}, ErrTooManyAttempts
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(url.PasswordHash), []byte(password)); err != nil {
+		g.recordFailure(url.ID, now)
+		return "", time.Time{}, ErrIncorrectPassword
+	}
+
+	expires := now.Add(g.unlockTTL)
+	return g.sign(url, expires), expires, nil
+}
+
+// IsUnlocked reports whether token is an unexpired unlock token for the URL's
+// current password
+func (g *LinkGuard) IsUnlocked(url *domain.URL, token string, now time.Time) bool {
+	expiresPart, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	expiresUnix, err := strconv.ParseInt(expiresPart, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	expires := time.Unix(expiresUnix, 0)
+	if !now.Before(expires) {
+		return false
+	}
+
+	return hmac.Equal([]byte(token), []byte(g.sign(url, expires)))
+}
+
+// sign builds an unlock token for a URL. The password hash is part of the
+// signed message, so changing the password revokes existing unlocks.
+func (g *LinkGuard) sign(url *domain.URL, expires time.Time) string {
+	expiresPart := strconv.FormatInt(expires.Unix(), 10)
+
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(strconv.FormatInt(url.ID, 10) + "\x00" + url.Alias + "\x00" + url.PasswordHash + "\x00" + expiresPart))
+
+	return expiresPart + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// allowAttempt reports whether another password may be tried for a URL
+func (g *LinkGuard) allowAttempt(urlID int64, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.sweep(now)
+
+	window, ok := g.failures[urlID]
+	if !ok || now.Sub(window.start) >= g.attemptWindow {
+		return true
+	}
+	return window.count < g.maxAttempts
+}
+
+// recordFailure counts a wrong password for a URL
+func (g *LinkGuard) recordFailure(urlID int64, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	window, ok := g.failures[urlID]
+	if !ok || now.Sub(window.start) >= g.attemptWindow {
+		window = &failureWindow{start: now}
+		g.failures[urlID] = window
+	}
+	window.count++
+}
+
+// sweep drops expired failure windows, at most once per window length, so
+// the map does not grow with every URL ever guessed at. It must be called
+// with g.mu held.
+func (g *LinkGuard) sweep(now time.Time) {
+	if now.Before(g.nextSweep) {
+		return
+	}
+	g.nextSweep = now.Add(g.attemptWindow)
+
+	for urlID, window := range g.failures {
+		if now.Sub(window.start) >= g.attemptWindow {
+			delete(g.failures, urlID)
+		}
+	}
+}
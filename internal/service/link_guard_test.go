@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// protectedURL returns a URL protected by password, hashed at the lowest
+// bcrypt cost to keep tests fast
+func protectedURL(t *testing.T, id int64, password string) *domain.URL {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	return &domain.URL{ID: id, Alias: "secret", PasswordHash: string(hash)}
+}
+
+func TestLinkGuardIsUnlocked(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	guard := NewLinkGuard("unlock-secret", 15*time.Minute, 5, 15*time.Minute)
+	url := protectedURL(t, 1, "hunter22")
+
+	token, expires, err := guard.Unlock(url, "hunter22", now)
+	if err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if !expires.Equal(now.Add(15 * time.Minute)) {
+		t.Fatalf("Unlock() expires at %v, want 15 minutes from now", expires)
+	}
+	expiresPart, signature, _ := strings.Cut(token, ".")
+
+	tampered := []byte(signature)
+	if tampered[0] == '0' {
+		tampered[0] = '1'
+	} else {
+		tampered[0] = '0'
+	}
+
+	changed := protectedURL(t, 1, "hunter33")
+	otherURL := *url
+	otherURL.ID = 2
+
+	tests := []struct {
+		name  string
+		guard *LinkGuard
+		url   *domain.URL
+		token string
+		now   time.Time
+		want  bool
+	}{
+		{name: "valid token", url: url, token: token, now: now, want: true},
+		{name: "valid until just before expiry", url: url, token: token, now: expires.Add(-time.Second), want: true},
+		{name: "tampered signature", url: url, token: expiresPart + "." + string(tampered), now: now},
+		{name: "tampered expiry", url: url, token: "9999999999." + signature, now: now},
+		{name: "expired token", url: url, token: token, now: expires},
+		{name: "issued before the password changed", url: changed, token: token, now: now},
+		{name: "other URL with the same alias", url: &otherURL, token: token, now: now},
+		{name: "other secret", guard: NewLinkGuard("other-secret", 0, 0, 0), url: url, token: token, now: now},
+		{name: "no separator", url: url, token: signature, now: now},
+		{name: "empty token", url: url, token: "", now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := guard
+			if tt.guard != nil {
+				g = tt.guard
+			}
+			if got := g.IsUnlocked(tt.url, tt.token, tt.now); got != tt.want {
+				t.Errorf("IsUnlocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkGuardLockout(t *testing.T) {
+	const maxAttempts = 3
+	window := 15 * time.Minute
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type attempt struct {
+		urlID    int64
+		password string
+		after    time.Duration // since start
+		wantErr  error
+	}
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "locked after too many wrong passwords",
+			attempts: []attempt{
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "right", wantErr: ErrTooManyAttempts},
+			},
+		},
+		{
+			name: "right password below the limit",
+			attempts: []attempt{
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "right"},
+			},
+		},
+		{
+			name: "lockout ends with the window",
+			attempts: []attempt{
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "right", after: window - time.Second, wantErr: ErrTooManyAttempts},
+				{urlID: 1, password: "right", after: window},
+			},
+		},
+		{
+			name: "lockout is per URL",
+			attempts: []attempt{
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 1, password: "wrong", wantErr: ErrIncorrectPassword},
+				{urlID: 2, password: "right"},
+				{urlID: 1, password: "right", wantErr: ErrTooManyAttempts},
+			},
+		},
+	}
+
+	urls := map[int64]*domain.URL{
+		1: protectedURL(t, 1, "right"),
+		2: protectedURL(t, 2, "right"),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := NewLinkGuard("unlock-secret", 0, maxAttempts, window)
+			for i, a := range tt.attempts {
+				_, _, err := guard.Unlock(urls[a.urlID], a.password, start.Add(a.after))
+				if !errors.Is(err, a.wantErr) {
+					t.Fatalf("attempt %d: Unlock() error = %v, want %v", i+1, err, a.wantErr)
+				}
+			}
+		})
+	}
+}
@@ -14,48 +14,162 @@ const (
 
 var (
 	ErrMaxRetriesExceeded = errors.New("maximum retries exceeded for generating unique alias")
+	ErrURLGone            = errors.New("URL has been deleted")
+	ErrURLExpired         = errors.New("URL has expired")
 )
 
 // URLService defines the interface for URL shortening business logic
 type URLService interface {
-	ShortenURL(originalURL string, alias string, userID int64) (*domain.URL, error)
-	GetURLByAlias(alias string) (*domain.URL, error)
-	IncrementClickCount(alias string) error
-	ListURLs(limit, offset int) ([]*domain.URL, error)
+	ShortenURL(req *domain.ShortenRequest, userID int64) (*domain.URL, error)
+	GetURLByAlias(hostname, alias string) (*domain.URL, error)
+	ResolveURL(host, alias string) (*domain.URL, error)
+	UpdateURL(url *domain.URL, req *domain.UpdateURLRequest, userID int64) (*domain.URL, error)
+	DeleteURL(url *domain.URL) error
+	PurgeURL(hostname, alias string) error
+	PurgeOwnURL(hostname, alias string, userID int64) error
+	FollowURL(url *domain.URL, visit *domain.Visit) (string, error)
+	ListURLs(limit, offset int, includeDeleted bool) ([]*domain.URL, error)
 	GetURLsByUserID(userID int64, limit, offset int) ([]*domain.URL, error)
+	GetURLsByWorkspaceID(workspaceID, userID int64, limit, offset int) ([]*domain.URL, error)
+	AuthorizeURL(url *domain.URL, userID int64, required string) error
 }
 
 type urlService struct {
 	repo        repository.URLRepository
+	presets     *repository.UTMPresetRepository
+	domains     *DomainService
+	workspaces  *WorkspaceService
+	analytics   AnalyticsService
 	baseURL     string
 	base62Chars string
 }
 
 // NewURLService creates a new URL service
-func NewURLService(repo repository.URLRepository, baseURL, base62Chars string) URLService {
+func NewURLService(repo repository.URLRepository, presets *repository.UTMPresetRepository, domains *DomainService, workspaces *WorkspaceService, analytics AnalyticsService, baseURL, base62Chars string) URLService {
 	return &urlService{
 		repo:        repo,
+		presets:     presets,
+		domains:     domains,
+		workspaces:  workspaces,
+		analytics:   analytics,
 		baseURL:     baseURL,
 		base62Chars: base62Chars,
 	}
 }
 
 // ShortenURL creates a shortened URL with automatic collision handling
-func (s *urlService) ShortenURL(originalURL string, alias string, userID int64) (*domain.URL, error) {
+func (s *urlService) ShortenURL(req *domain.ShortenRequest, userID int64) (*domain.URL, error) {
+	// Add UTM parameters before validating, so the stored URL is the one
+	// that is checked
+	if req.UTM != nil || req.UTMPreset != "" {
+		destination, err := s.applyUTM(req, userID)
+		if err != nil {
+			return nil, err
+		}
+		req.URL = destination
+	}
+
 	// Validate original URL
-	if err := domain.ValidateURL(originalURL); err != nil {
+	if err := domain.ValidateURL(req.URL); err != nil {
 		return nil, err
 	}
 
+	// Validate custom alias; generated ones are always valid
+	if err := domain.ValidateAlias(req.Alias); err != nil {
+		return nil, err
+	}
+
+	// Validate optional lifetime
+	if err := domain.ValidateExpiration(req.ExpiresAt, req.MaxClicks, req.ExpiredRedirectURL); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateTitle(req.Title); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateGeoRules(req.GeoRules); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateDeviceRules(req.DeviceRules); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateVariants(req.Variants); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateOpenGraph(req.OGTitle, req.OGDescription, req.OGImage); err != nil {
+		return nil, err
+	}
+
+	if req.QueryPolicy == "" {
+		req.QueryPolicy = domain.QueryPolicyDrop
+	}
+	if err := domain.ValidateQueryPolicy(req.QueryPolicy); err != nil {
+		return nil, err
+	}
+
+	// Validate redirect type; zero lets the repository apply the owner's default
+	if req.RedirectType != 0 {
+		if err := domain.ValidateRedirectType(req.RedirectType); err != nil {
+			return nil, err
+		}
+	}
+
 	url := &domain.URL{
-		OriginalURL: originalURL,
-		UserID:      userID,
-		ClickCount:  0,
+		OriginalURL:        req.URL,
+		UserID:             userID,
+		ClickCount:         0,
+		ExpiresAt:          req.ExpiresAt,
+		MaxClicks:          req.MaxClicks,
+		ExpiredRedirectURL: req.ExpiredRedirectURL,
+		RedirectType:       req.RedirectType,
+		Title:              req.Title,
+		ForcePreview:       req.ForcePreview,
+		GeoRules:           req.GeoRules,
+		DeviceRules:        req.DeviceRules,
+		Variants:           req.Variants,
+		QueryPolicy:        req.QueryPolicy,
+		OGTitle:            req.OGTitle,
+		OGDescription:      req.OGDescription,
+		OGImage:            req.OGImage,
+	}
+
+	// Create the URL in a workspace the user may add links to
+	if req.WorkspaceID != 0 {
+		if _, err := s.workspaces.Authorize(req.WorkspaceID, userID, domain.WorkspaceRoleEditor); err != nil {
+			return nil, err
+		}
+		url.WorkspaceID = req.WorkspaceID
+	}
+
+	// Serve the URL from one of the user's verified custom domains
+	if req.Domain != "" {
+		d, err := s.domains.FindForLinks(userID, req.Domain)
+		if err != nil {
+			return nil, err
+		}
+		url.DomainID = d.ID
+		url.Domain = d.Hostname
+	}
+
+	// Hash the optional link password
+	if req.Password != "" {
+		if err := domain.ValidateLinkPassword(req.Password); err != nil {
+			return nil, err
+		}
+		passwordHash, err := HashPassword(req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash link password: %w", err)
+		}
+		url.PasswordHash = passwordHash
 	}
 
 	// If custom alias is provided, use it directly
-	if alias != "" {
-		url.Alias = alias
+	if req.Alias != "" {
+		url.Alias = req.Alias
 		if err := s.repo.Create(url); err != nil {
 			return nil, err
 		}
@@ -88,9 +202,15 @@ func (s *urlService) ShortenURL(originalURL string, alias string, userID int64)
 	return nil, fmt.Errorf("%w: %v", ErrMaxRetriesExceeded, lastErr)
 }
 
-// GetURLByAlias retrieves URL information by alias
-func (s *urlService) GetURLByAlias(alias string) (*domain.URL, error) {
-	url, err := s.repo.FindByAlias(alias)
+// GetURLByAlias retrieves URL information by custom domain hostname and
+// alias, ignoring deleted URLs. An empty hostname stands for BASE_URL.
+func (s *urlService) GetURLByAlias(hostname, alias string) (*domain.URL, error) {
+	domainID, err := s.lookupHostname(hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.repo.FindByAlias(domainID, alias, false)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, repository.ErrNotFound
@@ -100,13 +220,293 @@ func (s *urlService) GetURLByAlias(alias string) (*domain.URL, error) {
 	return url, nil
 }
 
-// IncrementClickCount atomically increments the click counter
-func (s *urlService) IncrementClickCount(alias string) error {
-	return s.repo.IncrementClickCount(alias)
+// ResolveURL retrieves the URL to redirect to for an alias requested on a
+// host. Hosts other than verified custom domains serve the URLs of BASE_URL.
+// Unlike GetURLByAlias it tells deleted URLs apart by returning ErrURLGone.
+func (s *urlService) ResolveURL(host, alias string) (*domain.URL, error) {
+	domainID, err := s.domains.LookupHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve domain: %w", err)
+	}
+
+	url, err := s.repo.FindByAlias(domainID, alias, true)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to resolve URL: %w", err)
+	}
+
+	if url.DeletedAt != nil {
+		return nil, ErrURLGone
+	}
+
+	return url, nil
+}
+
+// UpdateURL applies the requested changes to an existing URL and persists them.
+// The alias is never changed, so links that were already shared keep working.
+// Moving the URL to another workspace requires userID to be an editor or owner
+// of it; a workspace ID of zero moves it back to its creator's personal URLs.
+func (s *urlService) UpdateURL(url *domain.URL, req *domain.UpdateURLRequest, userID int64) (*domain.URL, error) {
+	updated := *url
+
+	if req.WorkspaceID != nil && *req.WorkspaceID != url.WorkspaceID {
+		if err := s.workspaces.AuthorizeMoveOut(url, userID); err != nil {
+			return nil, err
+		}
+		if *req.WorkspaceID != 0 {
+			if _, err := s.workspaces.Authorize(*req.WorkspaceID, userID, domain.WorkspaceRoleEditor); err != nil {
+				return nil, err
+			}
+		}
+		updated.WorkspaceID = *req.WorkspaceID
+	}
+
+	if req.OriginalURL != nil {
+		if err := domain.ValidateURL(*req.OriginalURL); err != nil {
+			return nil, err
+		}
+		updated.OriginalURL = *req.OriginalURL
+	}
+
+	if (req.ExpiresAt != nil && req.ClearExpiresAt) || (req.MaxClicks != nil && req.ClearMaxClicks) {
+		return nil, domain.ErrSetAndClear
+	}
+	if req.ExpiresAt != nil || req.ClearExpiresAt {
+		updated.ExpiresAt = req.ExpiresAt
+	}
+	if req.MaxClicks != nil || req.ClearMaxClicks {
+		updated.MaxClicks = req.MaxClicks
+	}
+	if req.ExpiredRedirectURL != nil {
+		updated.ExpiredRedirectURL = *req.ExpiredRedirectURL
+	}
+	if req.ExpiresAt != nil || req.MaxClicks != nil || req.ExpiredRedirectURL != nil {
+		// Only a new expiration time has to be in the future; the merged
+		// settings must be valid as a whole
+		if err := domain.ValidateExpiration(req.ExpiresAt, updated.MaxClicks, updated.ExpiredRedirectURL); err != nil {
+			return nil, err
+		}
+	}
+	if req.MaxClicks != nil && *req.MaxClicks <= updated.CappedClicks() {
+		return nil, domain.ErrMaxClicksReached
+	}
+
+	if req.RedirectType != nil {
+		if err := domain.ValidateRedirectType(*req.RedirectType); err != nil {
+			return nil, err
+		}
+		updated.RedirectType = *req.RedirectType
+	}
+
+	if req.Title != nil {
+		if err := domain.ValidateTitle(*req.Title); err != nil {
+			return nil, err
+		}
+		updated.Title = *req.Title
+	}
+	if req.ForcePreview != nil {
+		updated.ForcePreview = *req.ForcePreview
+	}
+	if req.GeoRules != nil {
+		if err := domain.ValidateGeoRules(*req.GeoRules); err != nil {
+			return nil, err
+		}
+		updated.GeoRules = *req.GeoRules
+	}
+	if req.DeviceRules != nil {
+		if err := domain.ValidateDeviceRules(*req.DeviceRules); err != nil {
+			return nil, err
+		}
+		updated.DeviceRules = *req.DeviceRules
+	}
+	if req.Variants != nil {
+		if err := domain.ValidateVariants(*req.Variants); err != nil {
+			return nil, err
+		}
+		updated.Variants = *req.Variants
+	}
+	if req.QueryPolicy != nil {
+		if err := domain.ValidateQueryPolicy(*req.QueryPolicy); err != nil {
+			return nil, err
+		}
+		updated.QueryPolicy = *req.QueryPolicy
+	}
+	if req.OGTitle != nil {
+		updated.OGTitle = *req.OGTitle
+	}
+	if req.OGDescription != nil {
+		updated.OGDescription = *req.OGDescription
+	}
+	if req.OGImage != nil {
+		updated.OGImage = *req.OGImage
+	}
+	if req.OGTitle != nil || req.OGDescription != nil || req.OGImage != nil {
+		if err := domain.ValidateOpenGraph(updated.OGTitle, updated.OGDescription, updated.OGImage); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Password != nil {
+		updated.PasswordHash = ""
+		if *req.Password != "" {
+			if err := domain.ValidateLinkPassword(*req.Password); err != nil {
+				return nil, err
+			}
+			passwordHash, err := HashPassword(*req.Password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash link password: %w", err)
+			}
+			updated.PasswordHash = passwordHash
+		}
+	}
+
+	if err := s.repo.Update(&updated); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	return &updated, nil
+}
+
+// DeleteURL soft-deletes a URL. The alias stays reserved and redirects to it
+// report the link as gone.
+func (s *urlService) DeleteURL(url *domain.URL) error {
+	if err := s.repo.SoftDelete(url.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to delete URL: %w", err)
+	}
+	return nil
+}
+
+// PurgeURL permanently removes a URL and frees its alias on its domain
+func (s *urlService) PurgeURL(hostname, alias string) error {
+	domainID, err := s.lookupHostname(hostname)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Purge(domainID, alias); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to purge URL: %w", err)
+	}
+	return nil
+}
+
+// PurgeOwnURL permanently removes a URL, soft-deleted ones included, for its
+// creator or an owner of its workspace. Its click events go with it. This is
+// how users free their custom domains of deleted URLs.
+func (s *urlService) PurgeOwnURL(hostname, alias string, userID int64) error {
+	domainID, err := s.lookupHostname(hostname)
+	if err != nil {
+		return err
+	}
+
+	url, err := s.repo.FindByAlias(domainID, alias, true)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to get URL: %w", err)
+	}
+
+	if err := s.workspaces.AuthorizeURL(url, userID, domain.WorkspaceRoleOwner); err != nil {
+		return err
+	}
+
+	return s.PurgeURL(hostname, alias)
+}
+
+// lookupHostname maps the hostname of a custom domain to its ID, reporting
+// URLs on unknown domains as not found
+func (s *urlService) lookupHostname(hostname string) (int64, error) {
+	domainID, err := s.domains.LookupHostname(hostname)
+	if err != nil {
+		if errors.Is(err, domain.ErrDomainNotFound) {
+			return 0, repository.ErrNotFound
+		}
+		return 0, fmt.Errorf("failed to resolve domain: %w", err)
+	}
+	return domainID, nil
+}
+
+// applyUTM returns the destination of a shorten request with its UTM preset
+// and parameters merged in canonical form
+func (s *urlService) applyUTM(req *domain.ShortenRequest, userID int64) (string, error) {
+	var utm domain.UTMParams
+	if req.UTMPreset != "" {
+		preset, err := s.presets.FindByName(userID, req.UTMPreset)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return "", domain.ErrUTMPresetNotFound
+			}
+			return "", fmt.Errorf("failed to load UTM preset: %w", err)
+		}
+		utm = preset.UTMParams
+	}
+	if req.UTM != nil {
+		utm = utm.Override(*req.UTM)
+	}
+
+	if err := utm.Normalize(); err != nil {
+		return "", err
+	}
+
+	return domain.ApplyUTM(req.URL, utm)
+}
+
+// FollowURL records a click on a URL that is about to be followed and returns
+// the destination for the visitor: the device rule for the visitor's platform,
+// the first geo rule matching their country, the visitor's variant, or
+// original_url, with the request's query string passed on according to the
+// URL's query policy. The matched rule and variant are recorded with the click.
+//
+// Bots, link previewers and prefetches are recorded as bot clicks and never
+// count towards ClickCount or the click cap. Human clicks on links with a
+// click cap are claimed synchronously in the same statement that checks the
+// cap, so the cap holds under concurrent clicks. The click counters themselves
+// are written behind, with the click events. ErrURLExpired is returned once
+// the link is past its expiry time or click cap.
+func (s *urlService) FollowURL(url *domain.URL, visit *domain.Visit) (string, error) {
+	if url.IsExpired(visit.Time) {
+		return "", ErrURLExpired
+	}
+
+	s.analytics.EnrichVisit(visit)
+
+	destination, rule := url.Destination(visit.Platform, visit.Country)
+	visit.MatchedRule = rule
+	if rule == "" {
+		if variant, ok := url.PickVariant(visit.AssignedVariant, visit.VisitorHash); ok {
+			destination = variant.URL
+			visit.Variant = variant.Name
+		}
+	}
+	destination = domain.ApplyQueryPolicy(destination, visit.Query, url.QueryPolicy)
+
+	if url.MaxClicks != nil && !visit.IsBot {
+		claimed, err := s.repo.ClaimClick(url.DomainID, url.Alias)
+		if err != nil {
+			return "", fmt.Errorf("failed to count click: %w", err)
+		}
+		if !claimed {
+			return "", ErrURLExpired
+		}
+	}
+
+	s.analytics.RecordClick(url, visit)
+	return destination, nil
 }
 
 // ListURLs retrieves all URLs with pagination
-func (s *urlService) ListURLs(limit, offset int) ([]*domain.URL, error) {
+func (s *urlService) ListURLs(limit, offset int, includeDeleted bool) ([]*domain.URL, error) {
 	// Set default limit if not specified
 	if limit <= 0 {
 		limit = 50
@@ -117,10 +517,11 @@ func (s *urlService) ListURLs(limit, offset int) ([]*domain.URL, error) {
 		limit = 100
 	}
 
-	return s.repo.FindAll(limit, offset)
+	return s.repo.FindAll(limit, offset, includeDeleted)
 }
 
-// GetURLsByUserID retrieves all URLs created by a specific user with pagination
+// GetURLsByUserID retrieves the personal URLs created by a specific user with
+// pagination
 func (s *urlService) GetURLsByUserID(userID int64, limit, offset int) ([]*domain.URL, error) {
 	// Set default limit if not specified
 	if limit <= 0 {
@@ -132,5 +533,31 @@ func (s *urlService) GetURLsByUserID(userID int64, limit, offset int) ([]*domain
 		limit = 100
 	}
 
-	return s.repo.FindByUserID(userID, limit, offset)
+	return s.repo.FindByUserID(userID, limit, offset, false)
+}
+
+// GetURLsByWorkspaceID retrieves the URLs of a workspace with pagination, for
+// one of its members
+func (s *urlService) GetURLsByWorkspaceID(workspaceID, userID int64, limit, offset int) ([]*domain.URL, error) {
+	if _, err := s.workspaces.Authorize(workspaceID, userID, domain.WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+
+	// Set default limit if not specified
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// Prevent excessive limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	return s.repo.FindByWorkspaceID(workspaceID, limit, offset, false)
+}
+
+// AuthorizeURL checks that a user may do what requires the workspace role
+// required on a URL
+func (s *urlService) AuthorizeURL(url *domain.URL, userID int64, required string) error {
+	return s.workspaces.AuthorizeURL(url, userID, required)
 }
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/repository"
+)
+
+// UserService handles per-user settings and UTM presets
+type UserService struct {
+	userRepo   *repository.UserRepository
+	presetRepo *repository.UTMPresetRepository
+}
+
+// NewUserService creates a new user service
+func NewUserService(userRepo *repository.UserRepository, presetRepo *repository.UTMPresetRepository) *UserService {
+	return &UserService{
+		userRepo:   userRepo,
+		presetRepo: presetRepo,
+	}
+}
+
+// GetSettings returns the settings of a user
+func (s *UserService) GetSettings(userID int64) (*domain.UserSettings, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &domain.UserSettings{
+		DefaultRedirectType: user.DefaultRedirectType,
+	}, nil
+}
+
+// UpdateSettings applies the requested changes to the settings of a user and
+// returns the resulting settings
+func (s *UserService) UpdateSettings(userID int64, req *domain.UpdateUserSettingsRequest) (*domain.UserSettings, error) {
+	if req.DefaultRedirectType != nil {
+		if err := domain.ValidateRedirectType(*req.DefaultRedirectType); err != nil {
+			return nil, err
+		}
+
+		if err := s.userRepo.UpdateDefaultRedirectType(userID, *req.DefaultRedirectType); err != nil {
+			if err == repository.ErrNotFound {
+				return nil, domain.ErrUserNotFound
+			}
+			return nil, err
+		}
+	}
+
+	return s.GetSettings(userID)
+}
+
+// ListUTMPresets returns the UTM presets of a user, ordered by name
+func (s *UserService) ListUTMPresets(userID int64) ([]*domain.UTMPreset, error) {
+	return s.presetRepo.FindByUserID(userID)
+}
+
+// SaveUTMPreset creates or replaces a named UTM preset of a user. Values are
+// stored in canonical form, as they will be applied to URLs.
+func (s *UserService) SaveUTMPreset(userID int64, name string, params domain.UTMParams) (*domain.UTMPreset, error) {
+	if err := domain.ValidateUTMPresetName(name); err != nil {
+		return nil, err
+	}
+
+	if err := params.Normalize(); err != nil {
+		return nil, err
+	}
+	if params.IsEmpty() {
+		return nil, domain.ErrEmptyUTM
+	}
+
+	preset := &domain.UTMPreset{Name: name, UTMParams: params}
+	if err := s.presetRepo.Save(userID, preset); err != nil {
+		return nil, err
+	}
+
+	return preset, nil
+}
+
+// DeleteUTMPreset removes a named UTM preset of a user
+func (s *UserService) DeleteUTMPreset(userID int64, name string) error {
+	if err := s.presetRepo.Delete(userID, name); err != nil {
+		if err == repository.ErrNotFound {
+			return domain.ErrUTMPresetNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListUsers returns users ordered by ID, with pagination
+func (s *UserService) ListUsers(limit, offset int) ([]*domain.User, error) {
+	// Set default limit if not specified
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// Prevent excessive limit
+	if limit > 100 {
+		limit = 100
+	}
+
+	return s.userRepo.FindAll(limit, offset)
+}
+
+// GetRole returns the current role of a user
+func (s *UserService) GetRole(userID int64) (string, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return "", domain.ErrUserNotFound
+		}
+		return "", err
+	}
+
+	return user.Role, nil
+}
+
+// UpdateRole changes the role of a user. The last admin cannot be demoted.
+// The new role applies to the user's next request.
+func (s *UserService) UpdateRole(userID int64, role string) (*domain.User, error) {
+	if err := domain.ValidateRole(role); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateRole(userID, role); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
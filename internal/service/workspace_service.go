@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/Faleeeee/URL_Shortener/internal/domain"
+	"github.com/Faleeeee/URL_Shortener/internal/repository"
+)
+
+// WorkspaceService manages workspaces and their members, and decides what
+// users may do with the URLs of a workspace according to their role
+type WorkspaceService struct {
+	repo *repository.WorkspaceRepository
+	urls repository.URLRepository
+}
+
+// NewWorkspaceService creates a new workspace service. urls is told about
+// URLs that deleting a workspace detached, so it does not serve them from
+// its cache.
+func NewWorkspaceService(repo *repository.WorkspaceRepository, urls repository.URLRepository) *WorkspaceService {
+	return &WorkspaceService{repo: repo, urls: urls}
+}
+
+// Create creates a workspace owned by a user
+func (s *WorkspaceService) Create(userID int64, name string) (*domain.Workspace, error) {
+	name, err := domain.NormalizeWorkspaceName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &domain.Workspace{Name: name}
+	if err := s.repo.Create(ws, userID); err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// List returns the workspaces a user is a member of, with the user's role
+func (s *WorkspaceService) List(userID int64) ([]*domain.Workspace, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+// Delete removes a workspace. Only owners can remove it, and only once it no
+// longer has URLs; its soft-deleted URLs go back to their creators, so their
+// analytics are kept.
+func (s *WorkspaceService) Delete(workspaceID, userID int64) error {
+	if _, err := s.Authorize(workspaceID, userID, domain.WorkspaceRoleOwner); err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountActiveURLs(workspaceID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrWorkspaceInUse
+	}
+
+	if err := s.repo.Delete(workspaceID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrWorkspaceNotFound
+		}
+		return err
+	}
+	s.urls.ForgetWorkspace(workspaceID)
+
+	return nil
+}
+
+// ListMembers returns the members of a workspace to one of its members
+func (s *WorkspaceService) ListMembers(workspaceID, userID int64) ([]*domain.WorkspaceMember, error) {
+	if _, err := s.Authorize(workspaceID, userID, domain.WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+	return s.repo.FindMembers(workspaceID)
+}
+
+// AddMember adds a user to a workspace with a role. Only owners can add
+// members.
+func (s *WorkspaceService) AddMember(workspaceID, userID int64, req *domain.AddWorkspaceMemberRequest) (*domain.WorkspaceMember, error) {
+	if err := domain.ValidateWorkspaceRole(req.Role); err != nil {
+		return nil, err
+	}
+	if _, err := s.Authorize(workspaceID, userID, domain.WorkspaceRoleOwner); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.AddMember(workspaceID, req.Username, req.Role)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		if errors.Is(err, repository.ErrDuplicateMember) {
+			return nil, domain.ErrWorkspaceMemberExists
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// UpdateMember changes the role of a workspace member. Only owners can change
+// roles, and the last owner cannot step down.
+func (s *WorkspaceService) UpdateMember(workspaceID, userID, memberID int64, role string) error {
+	if err := domain.ValidateWorkspaceRole(role); err != nil {
+		return err
+	}
+	if _, err := s.Authorize(workspaceID, userID, domain.WorkspaceRoleOwner); err != nil {
+		return err
+	}
+
+	if role != domain.WorkspaceRoleOwner {
+		if err := s.keepOwner(workspaceID, memberID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.UpdateMemberRole(workspaceID, memberID, role); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrWorkspaceMemberNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RemoveMember removes a member from a workspace. Owners can remove anyone
+// and members can leave, but the last owner cannot.
+func (s *WorkspaceService) RemoveMember(workspaceID, userID, memberID int64) error {
+	required := domain.WorkspaceRoleOwner
+	if memberID == userID {
+		required = domain.WorkspaceRoleViewer
+	}
+	if _, err := s.Authorize(workspaceID, userID, required); err != nil {
+		return err
+	}
+
+	if err := s.keepOwner(workspaceID, memberID); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveMember(workspaceID, memberID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrWorkspaceMemberNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Authorize returns the role of a user in a workspace, checking that it
+// allows what requires the role required. Workspaces the user is not a
+// member of are reported as not found.
+func (s *WorkspaceService) Authorize(workspaceID, userID int64, required string) (string, error) {
+	role, err := s.repo.FindRole(workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", domain.ErrWorkspaceNotFound
+		}
+		return "", err
+	}
+
+	if !domain.WorkspaceRoleAllows(role, required) {
+		return role, domain.ErrWorkspaceForbidden
+	}
+
+	return role, nil
+}
+
+// AuthorizeURL checks that a user's role allows what requires the role
+// required on a URL. The creator of a personal URL has every right on it;
+// other users have none.
+func (s *WorkspaceService) AuthorizeURL(url *domain.URL, userID int64, required string) error {
+	if url.WorkspaceID == 0 {
+		if url.UserID != userID {
+			return domain.ErrWorkspaceForbidden
+		}
+		return nil
+	}
+
+	if _, err := s.Authorize(url.WorkspaceID, userID, required); err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			return domain.ErrWorkspaceForbidden
+		}
+		return err
+	}
+
+	return nil
+}
+
+// AuthorizeMoveOut checks that a user may take a URL out of its workspace,
+// into another workspace or back to the personal URLs of its creator
+func (s *WorkspaceService) AuthorizeMoveOut(url *domain.URL, userID int64) error {
+	if url.WorkspaceID == 0 {
+		return nil
+	}
+
+	role, err := s.Authorize(url.WorkspaceID, userID, domain.WorkspaceRoleEditor)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			return domain.ErrWorkspaceForbidden
+		}
+		return err
+	}
+	if !domain.CanMoveOutOfWorkspace(url, userID, role) {
+		return domain.ErrWorkspaceForbidden
+	}
+
+	return nil
+}
+
+// keepOwner returns ErrLastWorkspaceOwner if memberID is the only owner of a
+// workspace
+func (s *WorkspaceService) keepOwner(workspaceID, memberID int64) error {
+	role, err := s.repo.FindRole(workspaceID, memberID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrWorkspaceMemberNotFound
+		}
+		return err
+	}
+	if role != domain.WorkspaceRoleOwner {
+		return nil
+	}
+
+	owners, err := s.repo.CountOwners(workspaceID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return domain.ErrLastWorkspaceOwner
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+// Package useragent classifies User-Agent headers into device class, OS
+// family and browser family. It is pure Go and works offline: the rules are an
+// ordered table embedded from rules.json, where the first matching rule wins.
+package useragent
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Device classes
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+)
+
+// Other is reported for OS and browser families that no rule recognises
+const Other = "Other"
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Info is the classification of a single User-Agent header
+type Info struct {
+	Device  string `json:"device"`
+	OS      string `json:"os"`
+	Browser string `json:"browser"`
+	// Bot is the name of the matched crawler or HTTP client, if any
+	Bot string `json:"bot,omitempty"`
+	// Unfurler is set for crawlers that fetch links to show a preview card
+	// when they are shared, such as Slackbot or Twitterbot
+	Unfurler bool `json:"unfurler,omitempty"`
+}
+
+// IsBot reports whether the User-Agent belongs to a crawler or script
+func (i Info) IsBot() bool {
+	return i.Device == DeviceBot
+}
+
+// rule matches a User-Agent when pattern matches and exclude, if set, does not
+type rule struct {
+	Name     string `json:"name"`
+	Pattern  string `json:"pattern"`
+	Exclude  string `json:"exclude"`
+	Unfurler bool   `json:"unfurler"` // bot rules only
+
+	pattern *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+func (r *rule) compile() error {
+	var err error
+	if r.pattern, err = regexp.Compile("(?i)" + r.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern for %q: %w", r.Name, err)
+	}
+	if r.Exclude != "" {
+		if r.exclude, err = regexp.Compile("(?i)" + r.Exclude); err != nil {
+			return fmt.Errorf("invalid exclude pattern for %q: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *rule) matches(ua string) bool {
+	return r.pattern.MatchString(ua) && (r.exclude == nil || !r.exclude.MatchString(ua))
+}
+
+// ruleTable holds the ordered rules for each classification
+type ruleTable struct {
+	Bots     []*rule `json:"bots"`
+	Devices  []*rule `json:"devices"`
+	OS       []*rule `json:"os"`
+	Browsers []*rule `json:"browsers"`
+}
+
+// Parser classifies User-Agent headers using a rule table
+type Parser struct {
+	rules ruleTable
+}
+
+// NewParser creates a parser from the embedded rule table
+func NewParser() *Parser {
+	p, err := NewParserFromJSON(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("useragent: embedded rules are invalid: %v", err))
+	}
+	return p
+}
+
+// NewParserFromJSON creates a parser from a rule table in the rules.json format
+func NewParserFromJSON(data []byte) (*Parser, error) {
+	p := &Parser{}
+	if err := json.Unmarshal(data, &p.rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	for _, rules := range [][]*rule{p.rules.Bots, p.rules.Devices, p.rules.OS, p.rules.Browsers} {
+		for _, r := range rules {
+			if err := r.compile(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// Parse classifies a User-Agent header. An empty header is classified as a
+// bot, since browsers always send one.
+func (p *Parser) Parse(ua string) Info {
+	info := Info{
+		Device:  DeviceDesktop,
+		OS:      firstMatch(p.rules.OS, ua),
+		Browser: firstMatch(p.rules.Browsers, ua),
+	}
+
+	if ua == "" {
+		info.Device = DeviceBot
+		return info
+	}
+
+	if bot := firstRule(p.rules.Bots, ua); bot != nil {
+		info.Device = DeviceBot
+		info.Bot = bot.Name
+		info.Unfurler = bot.Unfurler
+		return info
+	}
+
+	if device := firstMatch(p.rules.Devices, ua); device != Other {
+		info.Device = device
+	}
+
+	return info
+}
+
+// firstMatch returns the name of the first rule matching ua, or Other
+func firstMatch(rules []*rule, ua string) string {
+	if r := firstRule(rules, ua); r != nil {
+		return r.Name
+	}
+	return Other
+}
+
+// firstRule returns the first rule matching ua, or nil
+func firstRule(rules []*rule, ua string) *rule {
+	for _, r := range rules {
+		if r.matches(ua) {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package useragent
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// corpusCase is a row of testdata/corpus.tsv
+type corpusCase struct {
+	line int
+	ua   string
+	want Info
+}
+
+// loadCorpus reads the User-Agent corpus
+func loadCorpus(t *testing.T) []corpusCase {
+	t.Helper()
+
+	f, err := os.Open("testdata/corpus.tsv")
+	if err != nil {
+		t.Fatalf("failed to open corpus: %v", err)
+	}
+	defer f.Close()
+
+	var cases []corpusCase
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 6 {
+			t.Fatalf("corpus line %d: expected 6 fields, got %d", line, len(fields))
+		}
+		unfurler, err := strconv.ParseBool(fields[4])
+		if err != nil {
+			t.Fatalf("corpus line %d: invalid unfurler %q", line, fields[4])
+		}
+		bot := fields[3]
+		if bot == "-" {
+			bot = ""
+		}
+
+		cases = append(cases, corpusCase{
+			line: line,
+			ua:   fields[5],
+			want: Info{Device: fields[0], OS: fields[1], Browser: fields[2], Bot: bot, Unfurler: unfurler},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read corpus: %v", err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("corpus is empty")
+	}
+
+	return cases
+}
+
+func TestParseCorpus(t *testing.T) {
+	p := NewParser()
+
+	for _, tc := range loadCorpus(t) {
+		if got := p.Parse(tc.ua); got != tc.want {
+			t.Errorf("corpus line %d: Parse(%q)\n got  %+v\n want %+v", tc.line, tc.ua, got, tc.want)
+		}
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	got := NewParser().Parse("")
+	want := Info{Device: DeviceBot, OS: Other, Browser: Other}
+	if got != want {
+		t.Errorf("Parse(\"\") = %+v, want %+v", got, want)
+	}
+	if !got.IsBot() {
+		t.Error("an empty User-Agent should be a bot")
+	}
+}
+
+func TestNewParserFromJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr bool
+	}{
+		{name: "valid", rules: `{"bots": [{"name": "curl", "pattern": "^curl/"}]}`},
+		{name: "malformed JSON", rules: `{"bots": [`, wantErr: true},
+		{name: "invalid pattern", rules: `{"os": [{"name": "broken", "pattern": "("}]}`, wantErr: true},
+		{name: "invalid exclude", rules: `{"browsers": [{"name": "broken", "pattern": "x", "exclude": "["}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParserFromJSON([]byte(tt.rules))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewParserFromJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -16,6 +16,9 @@ var (
 type Claims struct {
 	UserID   int64  `json:"user_id"`
 	Username string `json:"username"`
+	// Role is the user's role when the token was issued. It is informational
+	// only: RequireRole checks the current role in the database
+	Role string `json:"role"`
 	jwt.RegisteredClaims
 }
 
@@ -34,10 +37,11 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 }
 
 // GenerateToken generates a new JWT token
-func (m *JWTManager) GenerateToken(userID int64, username string) (string, error) {
+func (m *JWTManager) GenerateToken(userID int64, username, role string) (string, error) {
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
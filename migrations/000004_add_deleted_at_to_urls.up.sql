@@ -0,0 +1,5 @@
+ALTER TABLE urls
+ADD COLUMN deleted_at TIMESTAMP;
+
+-- Indexes for performance
+CREATE INDEX idx_deleted_at ON urls(deleted_at);
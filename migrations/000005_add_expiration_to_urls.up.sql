@@ -0,0 +1,7 @@
+ALTER TABLE urls
+ADD COLUMN expires_at TIMESTAMPTZ,
+ADD COLUMN max_clicks BIGINT,
+ADD COLUMN expired_redirect_url TEXT;
+
+-- Indexes for performance
+CREATE INDEX idx_expires_at ON urls(expires_at);
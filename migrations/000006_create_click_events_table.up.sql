@@ -0,0 +1,13 @@
+CREATE TABLE click_events (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    alias VARCHAR(16) NOT NULL,
+    clicked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    referrer TEXT,
+    user_agent TEXT,
+    ip_hash VARCHAR(64),
+    accept_language TEXT
+);
+
+-- Indexes for performance
+CREATE INDEX idx_click_events_url_id_clicked_at ON click_events(url_id, clicked_at);
@@ -0,0 +1,15 @@
+ALTER TABLE click_events
+ADD COLUMN referrer_host VARCHAR(255),
+ADD COLUMN utm_source VARCHAR(255),
+ADD COLUMN utm_medium VARCHAR(255),
+ADD COLUMN utm_campaign VARCHAR(255);
+
+-- Backfill referring hosts for clicks recorded before this migration
+UPDATE click_events
+SET referrer_host = CASE
+    WHEN referrer IS NULL THEN '(direct)'
+    ELSE COALESCE(
+        regexp_replace(lower(substring(referrer FROM '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/]*@)?([^/:?#]+)')), '^www\.', ''),
+        '(unknown)'
+    )
+END;
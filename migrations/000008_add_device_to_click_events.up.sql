@@ -0,0 +1,4 @@
+ALTER TABLE click_events
+ADD COLUMN device_class VARCHAR(16),
+ADD COLUMN os_family VARCHAR(64),
+ADD COLUMN browser_family VARCHAR(64);
@@ -0,0 +1,4 @@
+ALTER TABLE click_events
+ADD COLUMN country CHAR(2),
+ADD COLUMN region VARCHAR(128),
+ADD COLUMN city VARCHAR(128);
@@ -0,0 +1,8 @@
+ALTER TABLE urls
+ADD COLUMN bot_click_count BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE click_events
+ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Backfill bot flags from the device class recorded for existing clicks
+UPDATE click_events SET is_bot = TRUE WHERE device_class = 'bot';
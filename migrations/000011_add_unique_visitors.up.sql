@@ -0,0 +1,12 @@
+-- HyperLogLog sketches of distinct visitors (see internal/hll), keyed by a
+-- salted hash of IP and User-Agent. Bot clicks are not counted.
+ALTER TABLE urls
+ADD COLUMN unique_visitors BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN visitor_sketch BYTEA;
+
+CREATE TABLE url_daily_visitors (
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    sketch BYTEA NOT NULL,
+    PRIMARY KEY (url_id, day)
+);
@@ -0,0 +1,7 @@
+ALTER TABLE users
+ADD COLUMN default_redirect_type SMALLINT NOT NULL DEFAULT 302
+    CHECK (default_redirect_type IN (301, 302, 307, 308));
+
+ALTER TABLE urls
+ADD COLUMN redirect_type SMALLINT NOT NULL DEFAULT 302
+    CHECK (redirect_type IN (301, 302, 307, 308));
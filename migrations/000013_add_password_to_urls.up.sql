@@ -0,0 +1,3 @@
+-- bcrypt hash of the password protecting the link, if any
+ALTER TABLE urls
+ADD COLUMN password_hash TEXT;
@@ -0,0 +1,4 @@
+-- Title of the destination page, shown on the preview page if set
+ALTER TABLE urls
+ADD COLUMN title TEXT,
+ADD COLUMN force_preview BOOLEAN NOT NULL DEFAULT FALSE;
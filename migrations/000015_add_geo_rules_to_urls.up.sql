@@ -0,0 +1,4 @@
+-- Ordered list of {"country": [...], "url": "..."} rules, evaluated against
+-- the visitor's country before falling back to original_url
+ALTER TABLE urls
+ADD COLUMN geo_rules JSONB;
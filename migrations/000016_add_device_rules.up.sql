@@ -0,0 +1,8 @@
+-- Per-platform destinations, e.g. app store links for iOS and Android
+ALTER TABLE urls
+ADD COLUMN device_rules JSONB;
+
+-- Targeting rule that picked the destination of each click, e.g. "device:ios"
+-- or "geo:2"; NULL when original_url was used
+ALTER TABLE click_events
+ADD COLUMN matched_rule TEXT;
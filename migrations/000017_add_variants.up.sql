@@ -0,0 +1,7 @@
+-- Weighted destinations that visitors are split between, for A/B tests
+ALTER TABLE urls
+ADD COLUMN variants JSONB;
+
+-- Variant a click was sent to; NULL for links without variants
+ALTER TABLE click_events
+ADD COLUMN variant TEXT;
@@ -0,0 +1,4 @@
+-- How the query string of a short link request is passed on to the destination
+ALTER TABLE urls
+ADD COLUMN query_policy TEXT NOT NULL DEFAULT 'drop'
+    CHECK (query_policy IN ('drop', 'append', 'merge', 'override'));
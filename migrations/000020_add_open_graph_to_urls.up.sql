@@ -0,0 +1,5 @@
+-- Social preview overrides served to link unfurlers instead of the redirect
+ALTER TABLE urls
+ADD COLUMN og_title TEXT,
+ADD COLUMN og_description TEXT,
+ADD COLUMN og_image TEXT;
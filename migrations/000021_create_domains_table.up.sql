@@ -0,0 +1,26 @@
+-- Branded domains that users serve their short URLs from. A domain is only
+-- used once its owner proved control of it with a DNS TXT record.
+CREATE TABLE domains (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    hostname VARCHAR(253) NOT NULL,
+    verification_token TEXT NOT NULL,
+    verified_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (user_id, hostname)
+);
+
+-- Several users may claim a hostname, but only one can verify it
+CREATE UNIQUE INDEX idx_domains_verified_hostname ON domains(hostname) WHERE verified_at IS NOT NULL;
+
+-- URLs without a domain are served from BASE_URL. Aliases are unique per
+-- domain instead of globally. Deleting a domain must not take its URLs,
+-- soft-deleted ones included, and their click events with it: domains can
+-- only be removed once their owner purged every URL on them.
+ALTER TABLE urls
+ADD COLUMN domain_id BIGINT REFERENCES domains(id) ON DELETE RESTRICT;
+
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_alias_key;
+DROP INDEX IF EXISTS idx_alias;
+CREATE UNIQUE INDEX idx_urls_domain_alias ON urls(COALESCE(domain_id, 0), alias);
@@ -0,0 +1,27 @@
+-- Workspaces let teams share ownership of short URLs. Members have a role:
+-- viewers see links and their stats, editors also create and change links,
+-- and owners also manage members.
+CREATE TABLE workspaces (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE workspace_members (
+    workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- URLs without a workspace are personal URLs of their creator. Deleting a
+-- workspace must not take its URLs and their click events with it: URLs left
+-- in a deleted workspace become personal URLs of their creator.
+ALTER TABLE urls
+ADD COLUMN workspace_id BIGINT REFERENCES workspaces(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_urls_workspace_id ON urls(workspace_id);
@@ -0,0 +1,12 @@
+-- Site-wide roles: moderators can review every short URL, admins can also
+-- purge URLs and manage user roles
+ALTER TABLE users
+ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'));
+
+-- Accounts created before roles existed. Only these can be promoted from the
+-- legacy ADMIN_USERNAMES list, so names registered later never qualify.
+ALTER TABLE users
+ADD COLUMN predates_roles BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE users
+ALTER COLUMN predates_roles SET DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- click_count is only ever written together with the click_events it counts.
+-- Click caps are enforced on redirect against claimed_clicks instead, which
+-- ClaimClick raises to at least click_count + 1 on every capped click.
+ALTER TABLE urls
+ADD COLUMN claimed_clicks BIGINT NOT NULL DEFAULT 0;